@@ -0,0 +1,143 @@
+package chunkpipe
+
+import (
+	"cmp"
+	"context"
+)
+
+// Number 是 Sum 支援的數值類型
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64
+}
+
+// walkChunks 在讀鎖下依序對每個塊呼叫 fn，fn 返回 false 時停止。
+// fn 不可呼叫 cl 需要寫鎖的方法。
+func (cl *ChunkPipe[T]) walkChunks(fn func(chunk []T) bool) {
+	cl.rlock()
+	defer cl.mu.RUnlock()
+
+	list := cl.list
+	for i := range list {
+		if !fn(cl.load(&list[i])) {
+			return
+		}
+	}
+}
+
+// Fold 從 init 開始依序以 f 累積所有元素。
+// 聚合函式都在讀鎖下逐塊進行，傳入的函式不可修改 cp。
+func Fold[T, A any](cp *ChunkPipe[T], init A, f func(acc A, v T) A) A {
+	acc := init
+	cp.walkChunks(func(chunk []T) bool {
+		for _, v := range chunk {
+			acc = f(acc, v)
+		}
+		return true
+	})
+	return acc
+}
+
+// Reduce 以第一個元素為初始值依序以 f 累積其餘元素，ChunkPipe 為空時返回 false
+func Reduce[T any](cp *ChunkPipe[T], f func(acc, v T) T) (T, bool) {
+	var acc T
+	first := true
+	cp.walkChunks(func(chunk []T) bool {
+		for _, v := range chunk {
+			if first {
+				acc = v
+				first = false
+				continue
+			}
+			acc = f(acc, v)
+		}
+		return true
+	})
+	return acc, !first
+}
+
+// CountFunc 返回滿足 pred 的元素數
+func CountFunc[T any](cp *ChunkPipe[T], pred func(T) bool) int {
+	n := 0
+	cp.walkChunks(func(chunk []T) bool {
+		for _, v := range chunk {
+			if pred(v) {
+				n++
+			}
+		}
+		return true
+	})
+	return n
+}
+
+// MinFunc 以 cmp 比較並返回最小的元素，相等時返回最先出現者
+func MinFunc[T any](cp *ChunkPipe[T], cmp func(a, b T) int) (T, bool) {
+	return Reduce(cp, func(acc, v T) T {
+		if cmp(v, acc) < 0 {
+			return v
+		}
+		return acc
+	})
+}
+
+// MaxFunc 以 cmp 比較並返回最大的元素，相等時返回最先出現者
+func MaxFunc[T any](cp *ChunkPipe[T], cmp func(a, b T) int) (T, bool) {
+	return Reduce(cp, func(acc, v T) T {
+		if cmp(v, acc) > 0 {
+			return v
+		}
+		return acc
+	})
+}
+
+// Min 返回最小的元素
+func Min[T cmp.Ordered](cp *ChunkPipe[T]) (T, bool) {
+	return MinFunc(cp, cmp.Compare[T])
+}
+
+// Max 返回最大的元素
+func Max[T cmp.Ordered](cp *ChunkPipe[T]) (T, bool) {
+	return MaxFunc(cp, cmp.Compare[T])
+}
+
+// Sum 返回所有元素的總和
+func Sum[T Number](cp *ChunkPipe[T]) T {
+	var sum T
+	cp.walkChunks(func(chunk []T) bool {
+		for _, v := range chunk {
+			sum += v
+		}
+		return true
+	})
+	return sum
+}
+
+// ReduceChunks 以 partial 計算每個塊的部分結果，再按塊順序以 combine 合併。
+// workers 大於 1 時在 ChunkPipe 的快照上並行計算部分結果。ChunkPipe 為空時返回 false。
+func ReduceChunks[T, A any](cp *ChunkPipe[T], workers int, partial func(chunk []T) A, combine func(a, b A) A) (A, bool) {
+	var partials []A
+	if workers > 1 {
+		chunks := cp.snapshot()
+		partials = make([]A, len(chunks))
+		parallelChunks(context.Background(), len(chunks), workers, func(ctx context.Context, i int) error {
+			partials[i] = partial(chunks[i])
+			return nil
+		})
+	} else {
+		cp.walkChunks(func(chunk []T) bool {
+			partials = append(partials, partial(chunk))
+			return true
+		})
+	}
+
+	var acc A
+	if len(partials) == 0 {
+		return acc, false
+	}
+	acc = partials[0]
+	for _, p := range partials[1:] {
+		acc = combine(acc, p)
+	}
+	return acc, true
+}
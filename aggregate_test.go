@@ -0,0 +1,54 @@
+package chunkpipe
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAggregations(t *testing.T) {
+	cp := NewChunkPipe[float64]()
+	cp.Push([]float64{3, 1.5}).Push([]float64{-2}).Push([]float64{8, 0.5})
+
+	if got := Sum(cp); got != 11 {
+		t.Errorf("Sum = %v, want 11", got)
+	}
+	if got, ok := Min(cp); !ok || got != -2 {
+		t.Errorf("Min = %v, %v", got, ok)
+	}
+	if got, ok := Max(cp); !ok || got != 8 {
+		t.Errorf("Max = %v, %v", got, ok)
+	}
+	if got := CountFunc(cp, func(v float64) bool { return v > 1 }); got != 3 {
+		t.Errorf("CountFunc = %d, want 3", got)
+	}
+	if got, ok := Reduce(cp, func(a, b float64) float64 { return a * b }); !ok || got != -36 {
+		t.Errorf("Reduce = %v, %v", got, ok)
+	}
+	if got := Fold(cp, "", func(acc string, v float64) string { return acc + "x" }); got != "xxxxx" {
+		t.Errorf("Fold = %q", got)
+	}
+
+	t.Run("Empty", func(t *testing.T) {
+		empty := NewChunkPipe[int]()
+		if _, ok := Min(empty); ok {
+			t.Error("Min should return false for empty pipe")
+		}
+		if _, ok := ReduceChunks(empty, 4, func(c []int) int { return len(c) }, func(a, b int) int { return a + b }); ok {
+			t.Error("ReduceChunks should return false for empty pipe")
+		}
+	})
+
+	t.Run("ReduceChunks", func(t *testing.T) {
+		sp := NewChunkPipe[string]()
+		for _, s := range []string{"a", "b", "c", "d", "e"} {
+			sp.Push([]string{s, s})
+		}
+		join := func(c []string) string { return strings.Join(c, "") }
+		concat := func(a, b string) string { return a + b }
+		for _, workers := range []int{1, 4} {
+			if got, ok := ReduceChunks(sp, workers, join, concat); !ok || got != "aabbccddee" {
+				t.Errorf("ReduceChunks(workers=%d) = %q, %v", workers, got, ok)
+			}
+		}
+	})
+}
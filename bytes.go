@@ -0,0 +1,115 @@
+package chunkpipe
+
+import "bytes"
+
+// IndexByte 返回 ChunkPipe[byte] 中第一個 b 的索引，找不到時返回 -1
+func IndexByte(cp *ChunkPipe[byte], b byte) int {
+	cp.rlock()
+	defer cp.mu.RUnlock()
+	return indexByte(cp, b)
+}
+
+// Index 返回 ChunkPipe[byte] 中第一個 sep 的索引，sep 可以跨越塊邊界，找不到時返回 -1
+func Index(cp *ChunkPipe[byte], sep []byte) int {
+	cp.rlock()
+	defer cp.mu.RUnlock()
+	return indexBytes(cp, sep)
+}
+
+// ReadUntil 從頭部彈出直到並包含第一個 delim 的數據，以塊視圖返回。
+// 尚未出現 delim 時不修改 ChunkPipe 並返回 false。
+func ReadUntil(cp *ChunkPipe[byte], delim byte) ([][]byte, bool) {
+	var pe pendingEvent
+	defer pe.publish()
+	cp.lock()
+	defer cp.mu.Unlock()
+	cp.reclaim()
+
+	i := indexByte(cp, delim)
+	if i < 0 {
+		return nil, false
+	}
+	ret, n := cp.removeFront(i+1, true)
+	pe = cp.event(EventPopFront, n)
+	return ret, true
+}
+
+// ReadLine 從頭部彈出一行，以不含行尾 "\n" 或 "\r\n" 的塊視圖返回。
+// 尚未出現完整的一行時不修改 ChunkPipe 並返回 false。
+func ReadLine(cp *ChunkPipe[byte]) ([][]byte, bool) {
+	line, ok := ReadUntil(cp, '\n')
+	if !ok {
+		return nil, false
+	}
+	line = trimLastByte(line, '\n')
+	line = trimLastByte(line, '\r')
+	return line, true
+}
+
+// trimLastByte 移除塊視圖中最後一個位元組，前提是它等於 b
+func trimLastByte(views [][]byte, b byte) [][]byte {
+	if len(views) == 0 {
+		return views
+	}
+	last := views[len(views)-1]
+	if last[len(last)-1] != b {
+		return views
+	}
+	if len(last) == 1 {
+		return views[:len(views)-1]
+	}
+	views[len(views)-1] = last[:len(last)-1]
+	return views
+}
+
+// indexByte 需持有讀鎖
+func indexByte(cl *ChunkPipe[byte], b byte) int {
+	list := cl.list
+	for i := range list {
+		val := cl.load(&list[i])
+		if j := bytes.IndexByte(val, b); j >= 0 {
+			return cl.chunkStart(i) - cl.offset + j
+		}
+	}
+	return -1
+}
+
+// indexBytes 需持有讀鎖
+func indexBytes(cl *ChunkPipe[byte], sep []byte) int {
+	if len(sep) == 0 {
+		return 0
+	}
+
+	list := cl.list
+	for i := range list {
+		val := cl.load(&list[i])
+		if j := bytes.Index(val, sep); j >= 0 {
+			return cl.chunkStart(i) - cl.offset + j
+		}
+		// 塊內找不到時，檢查從塊尾開始並延伸到後續塊的匹配
+		for j := max(0, len(val)-len(sep)+1); j < len(val); j++ {
+			if matchAcross(cl, i, j, sep) {
+				return cl.chunkStart(i) - cl.offset + j
+			}
+		}
+	}
+	return -1
+}
+
+// matchAcross 回報從第 i 個塊的位置 j 開始的數據是否以 sep 開頭，需持有讀鎖
+func matchAcross(cl *ChunkPipe[byte], i, j int, sep []byte) bool {
+	list := cl.list
+	for ; i < len(list); i++ {
+		val := cl.load(&list[i])[j:]
+		n := min(len(val), len(sep))
+		if !bytes.Equal(val[:n], sep[:n]) {
+			return false
+		}
+		sep = sep[n:]
+		if len(sep) == 0 {
+			return true
+		}
+		j = 0
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package chunkpipe
+
+import (
+	"bytes"
+	"testing"
+)
+
+func newBytePipe(chunks ...string) *ChunkPipe[byte] {
+	cp := NewChunkPipe[byte]()
+	for _, c := range chunks {
+		cp.Push([]byte(c))
+	}
+	return cp
+}
+
+func joinViews(views [][]byte) string {
+	return string(bytes.Join(views, nil))
+}
+
+func TestIndexBytes(t *testing.T) {
+	cp := newBytePipe("hel", "lo\r", "\nwor", "l", "d")
+	if got := IndexByte(cp, 'w'); got != 7 {
+		t.Errorf("IndexByte = %d, want 7", got)
+	}
+	if got := IndexByte(cp, 'x'); got != -1 {
+		t.Errorf("IndexByte = %d, want -1", got)
+	}
+
+	tests := []struct {
+		sep  string
+		want int
+	}{
+		{"", 0},
+		{"hello", 0},
+		{"lo", 3},
+		{"\r\n", 5},
+		{"world", 7},
+		{"orld", 8},
+		{"worlds", -1},
+		{"x", -1},
+	}
+	for _, tt := range tests {
+		if got := Index(cp, []byte(tt.sep)); got != tt.want {
+			t.Errorf("Index(%q) = %d, want %d", tt.sep, got, tt.want)
+		}
+	}
+}
+
+func TestReadUntil(t *testing.T) {
+	cp := newBytePipe("GET / HT", "TP/1.1\r", "\nHost: x\n", "partial")
+
+	if line, ok := ReadLine(cp); !ok || joinViews(line) != "GET / HTTP/1.1" {
+		t.Errorf("ReadLine = %q, %v", joinViews(line), ok)
+	}
+	if line, ok := ReadUntil(cp, ':'); !ok || joinViews(line) != "Host:" {
+		t.Errorf("ReadUntil = %q, %v", joinViews(line), ok)
+	}
+	if line, ok := ReadLine(cp); !ok || joinViews(line) != " x" {
+		t.Errorf("ReadLine = %q, %v", joinViews(line), ok)
+	}
+	if _, ok := ReadLine(cp); ok {
+		t.Error("ReadLine should return false without a newline")
+	}
+	if cp.size() != len("partial") {
+		t.Errorf("ReadLine should not consume an incomplete line, size = %d", cp.size())
+	}
+
+	cp.Push([]byte("\n"))
+	if line, ok := ReadLine(cp); !ok || joinViews(line) != "partial" {
+		t.Errorf("ReadLine = %q, %v", joinViews(line), ok)
+	}
+}
@@ -0,0 +1,190 @@
+package chunkpipe
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// ChunkChan 返回逐塊取出數據的 channel，ChunkPipe 為空時等待新數據插入。
+// ChunkPipe 被關閉且取空或 ctx 結束時 channel 會被關閉，尚未送出的塊會被放回頭部。
+// mmap 段中的塊在送出前會被複製。
+func (cl *ChunkPipe[T]) ChunkChan(ctx context.Context) <-chan []T {
+	out := make(chan []T)
+	go func() {
+		defer close(out)
+		for {
+			val, meta, wait := cl.popChunkOrWait()
+			if val == nil {
+				if wait == nil {
+					return
+				}
+				select {
+				case <-wait:
+					continue
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			select {
+			case out <- val:
+			case <-ctx.Done():
+				cl.prependChunk(val, meta)
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// ToChan 返回逐個取出元素的 channel，行為與 ChunkChan 相同
+func (cl *ChunkPipe[T]) ToChan(ctx context.Context) <-chan T {
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		for {
+			val, meta, wait := cl.popChunkOrWait()
+			if val == nil {
+				if wait == nil {
+					return
+				}
+				select {
+				case <-wait:
+					continue
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			for i, v := range val {
+				select {
+				case out <- v:
+				case <-ctx.Done():
+					cl.prependChunk(val[i:], meta)
+					return
+				}
+			}
+		}
+	}()
+	return out
+}
+
+// FromChan 從 ch 讀取元素並批次插入 ChunkPipe：累積 batchSize 個元素，
+// 或第一個元素等待超過 maxDelay 時插入一個塊。maxDelay 小於等於 0 時只依大小分批。
+// ch 被關閉時插入剩餘元素並返回 nil；ctx 結束時插入剩餘元素並返回 ctx.Err()。
+// 插入失敗時（例如 ChunkPipe 已關閉時的 ErrClosed）停止讀取並返回 TryPush 的錯誤。
+func (cl *ChunkPipe[T]) FromChan(ctx context.Context, ch <-chan T, batchSize int, maxDelay time.Duration) error {
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+
+	var timer *time.Timer
+	var timeout <-chan time.Time
+	batch := make([]T, 0, batchSize)
+	flush := func() error {
+		if timeout != nil {
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			timeout = nil
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		err := cl.TryPush(batch)
+		batch = make([]T, 0, batchSize)
+		return err
+	}
+
+	for {
+		select {
+		case v, ok := <-ch:
+			if !ok {
+				return flush()
+			}
+			batch = append(batch, v)
+			if len(batch) >= batchSize {
+				if err := flush(); err != nil {
+					return err
+				}
+			} else if timeout == nil && maxDelay > 0 {
+				if timer == nil {
+					timer = time.NewTimer(maxDelay)
+				} else {
+					timer.Reset(maxDelay)
+				}
+				timeout = timer.C
+			}
+		case <-timeout:
+			timeout = nil
+			if err := flush(); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			if err := flush(); err != nil {
+				return err
+			}
+			return ctx.Err()
+		}
+	}
+}
+
+// popChunkOrWait 取出第一個塊；ChunkPipe 為空時返回等待新數據的 channel，
+// 已關閉且為空時兩者皆為 nil
+func (cl *ChunkPipe[T]) popChunkOrWait() ([]T, ChunkMeta, <-chan struct{}) {
+	var pe pendingEvent
+	defer pe.publish()
+	cl.lock()
+	defer cl.mu.Unlock()
+	cl.reclaim()
+
+	if len(cl.list) == 0 {
+		if cl.closed {
+			return nil, ChunkMeta{}, nil
+		}
+		return nil, ChunkMeta{}, cl.waitChan()
+	}
+
+	c := &cl.list[0]
+	val, meta := cl.detach(c), c.meta
+	_, n := cl.removeFront(c.off-cl.offset, false)
+	pe = cl.event(EventPopChunkFront, n)
+	return val, meta, nil
+}
+
+// prependChunk 將數據作為一個塊放回頭部，保留取出時的中繼資料
+func (cl *ChunkPipe[T]) prependChunk(val []T, meta ChunkMeta) {
+	if len(val) == 0 {
+		return
+	}
+
+	var pe pendingEvent
+	defer pe.publish()
+	cl.lock()
+	defer cl.mu.Unlock()
+	pe = cl.insertFront(val, meta)
+}
+
+// insertFront 將數據作為一個塊插入頭部並保留原本的中繼資料，meta 的 ID 為零時分配新的中繼資料。
+// 返回解鎖後要發布的插入事件，指標記錄為放回而不是插入，需持有寫鎖。
+func (cl *ChunkPipe[T]) insertFront(val []T, meta ChunkMeta) pendingEvent {
+	if meta.ID == 0 {
+		meta = cl.newMeta()
+	}
+	cl.list = slices.Insert(cl.list, 0, chunk[T]{
+		off:    cl.offset,
+		val:    val,
+		rawLen: len(val),
+		meta:   meta,
+	})
+	cl.offset -= len(val)
+	if !meta.Expires.IsZero() {
+		cl.scheduleExpiry(meta.Expires)
+	}
+	cl.signal()
+	return cl.requeueEvent(len(val))
+}
@@ -0,0 +1,81 @@
+package chunkpipe
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestChanBridges(t *testing.T) {
+	t.Run("ChunkChan", func(t *testing.T) {
+		cp := NewChunkPipe[int]()
+		cp.Push([]int{1, 2}).Push([]int{3})
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			cp.Push([]int{4, 5})
+			cp.Close()
+		}()
+
+		var got [][]int
+		for c := range cp.ChunkChan(context.Background()) {
+			got = append(got, c)
+		}
+		if want := [][]int{{1, 2}, {3}, {4, 5}}; !reflect.DeepEqual(got, want) {
+			t.Errorf("ChunkChan = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("ToChanCancel", func(t *testing.T) {
+		cp := NewChunkPipe[int]()
+		cp.Push([]int{1, 2, 3})
+		ctx, cancel := context.WithCancel(context.Background())
+		ch := cp.ToChan(ctx)
+		if v := <-ch; v != 1 {
+			t.Errorf("first value = %d, want 1", v)
+		}
+		cancel()
+		for range ch {
+		}
+		if got := cp.ValueSlice(); !reflect.DeepEqual(got, []int{2, 3}) {
+			t.Errorf("unsent values = %v, want [2 3]", got)
+		}
+		if v, ok := cp.Get(0); !ok || v != 2 {
+			t.Errorf("Get(0) = %v, %v", v, ok)
+		}
+	})
+
+	t.Run("FromChan", func(t *testing.T) {
+		cp := NewChunkPipe[int]()
+		ch := make(chan int)
+		done := make(chan error)
+		go func() {
+			done <- cp.FromChan(context.Background(), ch, 3, 20*time.Millisecond)
+		}()
+
+		for i := 1; i <= 4; i++ {
+			ch <- i
+		}
+		// 第四個元素在逾時後單獨成塊
+		time.Sleep(50 * time.Millisecond)
+		ch <- 5
+		close(ch)
+		if err := <-done; err != nil {
+			t.Fatal(err)
+		}
+		if got, want := cp.ChunkSlice(), [][]int{{1, 2, 3}, {4}, {5}}; !reflect.DeepEqual(got, want) {
+			t.Errorf("FromChan chunks = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("FromChanClosedPipe", func(t *testing.T) {
+		cp := NewChunkPipe[int]()
+		cp.Close()
+		ch := make(chan int, 1)
+		ch <- 1
+		close(ch)
+		if err := cp.FromChan(context.Background(), ch, 4, 0); err != ErrClosed {
+			t.Errorf("FromChan on closed pipe = %v, want ErrClosed", err)
+		}
+	})
+}
@@ -71,6 +71,17 @@ func benchmarkPopFront(b *testing.B, n int, m int) {
 	}
 }
 
+func benchmarkPopFrontN(b *testing.B, n int, m int, k int) {
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		cp := generateData(n, m)
+		b.StartTimer()
+		for j := 0; j < n*m; j += k {
+			cp.PopFrontN(k)
+		}
+	}
+}
+
 func benchmarkPopChunkEnd(b *testing.B, n int, m int) {
 	for i := 0; i < b.N; i++ {
 		b.StopTimer()
@@ -147,6 +158,14 @@ func BenchmarkPopFront10000x10(b *testing.B) {
 	benchmarkPopFront(b, 10000, 10)
 }
 
+func BenchmarkPopFrontN10x10000(b *testing.B) {
+	benchmarkPopFrontN(b, 10, 10000, 64)
+}
+
+func BenchmarkPopFrontN1000x100(b *testing.B) {
+	benchmarkPopFrontN(b, 1000, 100, 64)
+}
+
 func BenchmarkPopChunkFront10x10000(b *testing.B) {
 	benchmarkPopChunkFront(b, 10, 10000)
 }
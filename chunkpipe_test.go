@@ -2,6 +2,7 @@ package chunkpipe
 
 import (
 	"fmt"
+	"reflect"
 	"testing"
 )
 
@@ -312,3 +313,145 @@ func TestChunkIteratorEdgeCases(t *testing.T) {
 		}
 	})
 }
+
+func TestPeek(t *testing.T) {
+	cp := NewChunkPipe[int]()
+	if _, ok := cp.Front(); ok {
+		t.Error("Front should return false for empty pipe")
+	}
+	if _, ok := cp.PeekChunkEnd(); ok {
+		t.Error("PeekChunkEnd should return false for empty pipe")
+	}
+
+	cp.Push([]int{1, 2, 3}).Push([]int{4}).Push([]int{5, 6})
+	if v, ok := cp.Front(); !ok || v != 1 {
+		t.Errorf("Front = %v, %v", v, ok)
+	}
+	if v, ok := cp.Back(); !ok || v != 6 {
+		t.Errorf("Back = %v, %v", v, ok)
+	}
+	if c, ok := cp.PeekChunkFront(); !ok || len(c) != 3 {
+		t.Errorf("PeekChunkFront = %v, %v", c, ok)
+	}
+	if c, ok := cp.PeekChunkEnd(); !ok || len(c) != 2 || c[1] != 6 {
+		t.Errorf("PeekChunkEnd = %v, %v", c, ok)
+	}
+
+	views := cp.PeekN(5)
+	if len(views) != 3 || len(views[2]) != 1 || views[2][0] != 5 {
+		t.Errorf("PeekN(5) = %v", views)
+	}
+	if views := cp.PeekN(100); len(views) != 3 {
+		t.Errorf("PeekN(100) = %v", views)
+	}
+	if views := cp.PeekN(0); len(views) != 0 {
+		t.Errorf("PeekN(0) = %v", views)
+	}
+	if cp.size() != 6 {
+		t.Errorf("peeking should not consume data, size = %d", cp.size())
+	}
+}
+
+func TestPopN(t *testing.T) {
+	newPipe := func() *ChunkPipe[int] {
+		cp := NewChunkPipe[int]()
+		cp.Push([]int{1, 2, 3}).Push([]int{4}).Push([]int{5, 6, 7})
+		return cp
+	}
+
+	t.Run("PopFrontN", func(t *testing.T) {
+		cp := newPipe()
+		got := cp.PopFrontN(5)
+		if !reflect.DeepEqual(got, [][]int{{1, 2, 3}, {4}, {5}}) {
+			t.Errorf("PopFrontN(5) = %v", got)
+		}
+		if v, ok := cp.Get(0); !ok || v != 6 {
+			t.Errorf("Get(0) = %v, %v", v, ok)
+		}
+		if got := cp.PopFrontN(10); !reflect.DeepEqual(got, [][]int{{6, 7}}) {
+			t.Errorf("PopFrontN(10) = %v", got)
+		}
+		if got := cp.PopFrontN(1); got != nil {
+			t.Errorf("PopFrontN on empty pipe = %v", got)
+		}
+	})
+
+	t.Run("PopEndN", func(t *testing.T) {
+		cp := newPipe()
+		got := cp.PopEndN(5)
+		if !reflect.DeepEqual(got, [][]int{{3}, {4}, {5, 6, 7}}) {
+			t.Errorf("PopEndN(5) = %v", got)
+		}
+		if v, ok := cp.Back(); !ok || v != 2 {
+			t.Errorf("Back = %v, %v", v, ok)
+		}
+		cp.Push([]int{8})
+		if got := cp.ValueSlice(); !reflect.DeepEqual(got, []int{1, 2, 8}) {
+			t.Errorf("ValueSlice = %v", got)
+		}
+	})
+
+	t.Run("PopFrontInto", func(t *testing.T) {
+		cp := newPipe()
+		dst := make([]int, 4)
+		if n := cp.PopFrontInto(dst[:2]); n != 2 || dst[1] != 2 {
+			t.Errorf("PopFrontInto = %d, %v", n, dst)
+		}
+		if n := cp.PopFrontInto(dst); n != 4 || !reflect.DeepEqual(dst, []int{3, 4, 5, 6}) {
+			t.Errorf("PopFrontInto = %d, %v", n, dst)
+		}
+		if n := cp.PopFrontInto(dst); n != 1 || dst[0] != 7 {
+			t.Errorf("PopFrontInto = %d, %v", n, dst)
+		}
+		if cp.size() != 0 {
+			t.Errorf("size = %d, want 0", cp.size())
+		}
+	})
+}
+
+func TestDiscardTruncate(t *testing.T) {
+	newPipe := func() *ChunkPipe[int] {
+		cp := NewChunkPipe[int]()
+		cp.Push([]int{1, 2, 3}).Push([]int{4}).Push([]int{5, 6, 7})
+		return cp
+	}
+
+	t.Run("Discard", func(t *testing.T) {
+		cp := newPipe()
+		if n := cp.Discard(4); n != 4 {
+			t.Errorf("Discard(4) = %d", n)
+		}
+		if v, ok := cp.Get(0); !ok || v != 5 {
+			t.Errorf("Get(0) = %v, %v", v, ok)
+		}
+		if n := cp.Discard(1); n != 1 {
+			t.Errorf("Discard(1) = %d", n)
+		}
+		if got := cp.ValueSlice(); !reflect.DeepEqual(got, []int{6, 7}) {
+			t.Errorf("ValueSlice = %v", got)
+		}
+		if n := cp.Discard(10); n != 2 {
+			t.Errorf("Discard(10) = %d", n)
+		}
+	})
+
+	t.Run("Truncate", func(t *testing.T) {
+		cp := newPipe()
+		if n := cp.Truncate(2); n != 5 {
+			t.Errorf("Truncate(2) = %d", n)
+		}
+		cp.Push([]int{9})
+		if got := cp.ValueSlice(); !reflect.DeepEqual(got, []int{1, 2, 9}) {
+			t.Errorf("ValueSlice = %v", got)
+		}
+		if v, ok := cp.Get(2); !ok || v != 9 {
+			t.Errorf("Get(2) = %v, %v", v, ok)
+		}
+		if n := cp.Truncate(10); n != 0 {
+			t.Errorf("Truncate(10) = %d", n)
+		}
+		if n := cp.Truncate(0); n != 3 || cp.size() != 0 {
+			t.Errorf("Truncate(0) = %d, size = %d", n, cp.size())
+		}
+	})
+}
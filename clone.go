@@ -0,0 +1,116 @@
+package chunkpipe
+
+import (
+	"slices"
+	"time"
+)
+
+// newLike 建立與 cl 使用相同壓縮、編碼與 TTL 設定的空 ChunkPipe，需持有讀鎖。
+// mmap 段屬於原本的 ChunkPipe，新 ChunkPipe 將數據保存在堆上。
+func (cl *ChunkPipe[T]) newLike() *ChunkPipe[T] {
+	ret := NewChunkPipe[T]()
+	if cl.comp != nil {
+		ret.comp = &compression{
+			codec:     cl.comp.codec,
+			threshold: cl.comp.threshold,
+		}
+	}
+	ret.flatJSON = cl.flatJSON
+	ret.ttl = cl.ttl
+	ret.onExpire = cl.onExpire
+	return ret
+}
+
+// Clone 返回淺複製的 ChunkPipe，新舊 ChunkPipe 共用塊的內容，但可以各自插入、彈出與重新排列，
+// Reverse 與 Swap 會在修改共用的塊之前先複製。
+// 存放在 mmap 段中的塊會被複製到堆上。
+func (cl *ChunkPipe[T]) Clone() *ChunkPipe[T] {
+	return cl.clone(false)
+}
+
+// DeepClone 返回深複製的 ChunkPipe，所有塊的內容都會被複製
+func (cl *ChunkPipe[T]) DeepClone() *ChunkPipe[T] {
+	return cl.clone(true)
+}
+
+func (cl *ChunkPipe[T]) clone(deep bool) *ChunkPipe[T] {
+	// 淺複製需要標記原本的塊為共用，因此取得寫鎖
+	cl.lock()
+	defer cl.mu.Unlock()
+
+	ret := cl.newLike()
+	// 之後插入的塊的 ID 不與複製的塊重複
+	ret.lastID = cl.lastID
+	var next time.Time
+	for i := range cl.list {
+		c := &cl.list[i]
+		if exp := c.meta.Expires; !exp.IsZero() && (next.IsZero() || exp.Before(next)) {
+			next = exp
+		}
+		val, packed := c.val, c.packed
+		if deep || c.seg != nil {
+			val = slices.Clone(val)
+		}
+		if deep {
+			packed = slices.Clone(packed)
+		}
+		// 壓縮的塊解壓縮時會建立新的切片，不需要標記
+		shared := !deep && c.seg == nil && c.packed == nil
+		if shared {
+			c.shared = true
+		}
+		ret.list = append(ret.list, chunk[T]{
+			off:    c.off - cl.offset,
+			val:    val,
+			packed: packed,
+			rawLen: c.rawLen,
+			shared: shared,
+			meta:   c.meta,
+		})
+	}
+	if !next.IsZero() {
+		// 複製出的塊保留原本的到期時間
+		ret.lock()
+		ret.scheduleExpiry(next)
+		ret.mu.Unlock()
+	}
+	return ret
+}
+
+// Equal 回報兩個 ChunkPipe 的元素序列是否相同，不考慮塊邊界
+func Equal[T comparable](a, b *ChunkPipe[T]) bool {
+	return EqualFunc(a, b, func(x, y T) bool { return x == y })
+}
+
+// EqualFunc 以 eq 比較兩個 ChunkPipe 的元素序列，不考慮塊邊界
+func EqualFunc[T, U any](a *ChunkPipe[T], b *ChunkPipe[U], eq func(T, U) bool) bool {
+	ac, bc := a.snapshot(), b.snapshot()
+
+	var x []T
+	var y []U
+	for {
+		for len(x) == 0 && len(ac) != 0 {
+			x, ac = ac[0], ac[1:]
+		}
+		for len(y) == 0 && len(bc) != 0 {
+			y, bc = bc[0], bc[1:]
+		}
+		if len(x) == 0 || len(y) == 0 {
+			return len(x) == len(y)
+		}
+
+		n := min(len(x), len(y))
+		for i := 0; i < n; i++ {
+			if !eq(x[i], y[i]) {
+				return false
+			}
+		}
+		x, y = x[n:], y[n:]
+	}
+}
+
+// EqualChunks 回報兩個 ChunkPipe 的塊結構與內容是否都相同
+func EqualChunks[T comparable](a, b *ChunkPipe[T]) bool {
+	ac, bc := a.snapshot(), b.snapshot()
+	return slices.EqualFunc(ac, bc, slices.Equal[[]T])
+}
@@ -0,0 +1,76 @@
+package chunkpipe
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestEqual(t *testing.T) {
+	a := NewChunkPipe[int]()
+	a.Push([]int{1, 2}).Push([]int{3})
+	b := NewChunkPipe[int]()
+	b.Push([]int{1}).Push([]int{2, 3})
+
+	if !Equal(a, b) {
+		t.Error("Equal should ignore chunk boundaries")
+	}
+	if EqualChunks(a, b) {
+		t.Error("EqualChunks should compare chunk boundaries")
+	}
+	if !EqualChunks(a, a.Clone()) {
+		t.Error("EqualChunks should be true for a clone")
+	}
+
+	b.Push([]int{4})
+	if Equal(a, b) || Equal(b, a) {
+		t.Error("Equal should be false for different lengths")
+	}
+	if !EqualFunc(a, NewChunkPipe[int]().Push([]int{2, 4, 6}), func(x, y int) bool { return x*2 == y }) {
+		t.Error("EqualFunc mismatch")
+	}
+	if !Equal(NewChunkPipe[int](), NewChunkPipe[int]()) {
+		t.Error("empty pipes should be equal")
+	}
+}
+
+func TestClone(t *testing.T) {
+	data := []byte("hello")
+	cp := NewChunkPipe[byte]()
+	cp.Push(data).Push([]byte("world"))
+	cp.PopFront()
+
+	shallow := cp.Clone()
+	deep := cp.DeepClone()
+	data[1] = 'E'
+
+	if v, _ := shallow.Get(0); v != 'E' {
+		t.Errorf("Clone should share chunk payloads, got %q", v)
+	}
+	if v, _ := deep.Get(0); v != 'e' {
+		t.Errorf("DeepClone should copy chunk payloads, got %q", v)
+	}
+
+	shallow.PopChunkFront()
+	if cp.size() != 9 || shallow.size() != 5 {
+		t.Errorf("sizes = %d, %d, want 9, 5", cp.size(), shallow.size())
+	}
+	if got := deep.ValueSlice(); !bytes.Equal(got, []byte("elloworld")) {
+		t.Errorf("DeepClone = %q", got)
+	}
+}
+
+func TestCloneReorderIndependent(t *testing.T) {
+	cp := NewChunkPipe[int]()
+	cp.Push([]int{1, 2, 3}).Push([]int{4, 5})
+	c := cp.Clone()
+
+	cp.Reverse()
+	c.Swap(0, 4)
+	if got := c.ValueSlice(); !reflect.DeepEqual(got, []int{5, 2, 3, 4, 1}) {
+		t.Errorf("clone = %v", got)
+	}
+	if got := cp.ValueSlice(); !reflect.DeepEqual(got, []int{5, 4, 3, 2, 1}) {
+		t.Errorf("original = %v", got)
+	}
+}
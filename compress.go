@@ -0,0 +1,176 @@
+package chunkpipe
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/golang/snappy"
+)
+
+// 解壓縮快取保留的塊數
+const decodedCacheSize = 8
+
+// Codec 是塊壓縮使用的編解碼器
+type Codec interface {
+	// Name 返回編解碼器名稱
+	Name() string
+	// Encode 返回 src 壓縮後的內容，dst 足夠大時可以重用其空間
+	Encode(dst, src []byte) []byte
+	// Decode 返回 src 解壓縮後的內容，dst 足夠大時可以重用其空間
+	Decode(dst, src []byte) ([]byte, error)
+}
+
+type snappyCodec struct{}
+
+func (snappyCodec) Name() string { return "snappy" }
+
+func (snappyCodec) Encode(dst, src []byte) []byte {
+	return snappy.Encode(dst, src)
+}
+
+func (snappyCodec) Decode(dst, src []byte) ([]byte, error) {
+	return snappy.Decode(dst, src)
+}
+
+// Snappy 是內建的 snappy 編解碼器
+var Snappy Codec = snappyCodec{}
+
+// compression 保存壓縮設定與統計
+type compression struct {
+	codec     Codec
+	threshold int
+	cache     decodedCache
+	// 已壓縮塊的累計原始大小與壓縮後大小
+	rawBytes    atomic.Int64
+	packedBytes atomic.Int64
+	// 解壓縮失敗的次數與第一個錯誤
+	decodeErrors atomic.Int64
+	firstErr     atomic.Pointer[error]
+}
+
+// decodedCache 是最近解壓縮的塊的小型快取，讓順序讀取不必重複解壓縮
+type decodedCache struct {
+	mu   sync.Mutex
+	keys [decodedCacheSize]*byte
+	vals [decodedCacheSize][]byte
+	next int
+}
+
+// WithCompression 讓 ChunkPipe[byte] 在 Push 時壓縮長度不小於 threshold 的塊，
+// 並在 Get、彈出與迭代時才解壓縮。codec 為 nil 時使用 Snappy。
+// 壓縮後沒有變小的塊以原始形式保存。解壓縮失敗時該塊會被讀成等長的零值，
+// 失敗次數記錄在 Stats 的 DecodeErrors，第一個錯誤可以用 DecodeErr 取得。
+func WithCompression(codec Codec, threshold int) Option[byte] {
+	if codec == nil {
+		codec = Snappy
+	}
+	return func(cp *ChunkPipe[byte]) {
+		cp.comp = &compression{
+			codec:     codec,
+			threshold: threshold,
+		}
+	}
+}
+
+// encode 壓縮數據，壓縮後沒有變小時返回 nil
+func (c *compression) encode(data []byte) []byte {
+	packed := c.codec.Encode(nil, data)
+	if len(packed) == 0 || len(packed) >= len(data) {
+		return nil
+	}
+	c.rawBytes.Add(int64(len(data)))
+	c.packedBytes.Add(int64(len(packed)))
+	return packed
+}
+
+// decode 解壓縮長度為 n 的塊，失敗時記錄錯誤並返回 n 個零值
+func (c *compression) decode(packed []byte, n int) []byte {
+	key := &packed[0]
+	if val := c.cache.get(key); val != nil {
+		return val
+	}
+	val, err := c.codec.Decode(nil, packed)
+	if err == nil && len(val) != n {
+		err = fmt.Errorf("decoded %d bytes, want %d", len(val), n)
+	}
+	if err != nil {
+		err = fmt.Errorf("chunkpipe: %s decode chunk: %w", c.codec.Name(), err)
+		c.decodeErrors.Add(1)
+		c.firstErr.CompareAndSwap(nil, &err)
+		val = make([]byte, n)
+	}
+	c.cache.put(key, val)
+	return val
+}
+
+// DecodeErr 返回第一次解壓縮失敗的錯誤，未啟用壓縮或沒有失敗時返回 nil
+func (cl *ChunkPipe[T]) DecodeErr() error {
+	if cl.comp == nil {
+		return nil
+	}
+	if err := cl.comp.firstErr.Load(); err != nil {
+		return *err
+	}
+	return nil
+}
+
+func (dc *decodedCache) get(key *byte) []byte {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+
+	for i, k := range dc.keys {
+		if k == key {
+			return dc.vals[i]
+		}
+	}
+	return nil
+}
+
+func (dc *decodedCache) put(key *byte, val []byte) {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+
+	dc.keys[dc.next] = key
+	dc.vals[dc.next] = val
+	dc.next = (dc.next + 1) % decodedCacheSize
+}
+
+func (dc *decodedCache) drop(key *byte) {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+
+	for i, k := range dc.keys {
+		if k == key {
+			dc.keys[i] = nil
+			dc.vals[i] = nil
+		}
+	}
+}
+
+// packChunk 在啟用壓縮且塊夠大時壓縮數據
+func (cl *ChunkPipe[T]) packChunk(data []T) []byte {
+	b, ok := any(data).([]byte)
+	if !ok || len(b) < cl.comp.threshold {
+		return nil
+	}
+	return cl.comp.encode(b)
+}
+
+// load 返回塊的內容，必要時解壓縮
+func (cl *ChunkPipe[T]) load(c *chunk[T]) []T {
+	if c.packed == nil {
+		return c.val
+	}
+	return any(cl.comp.decode(c.packed, c.rawLen)).([]T)
+}
+
+// unpack 將塊解壓縮後就地保存，在修改塊內容前呼叫，需持有寫鎖
+func (cl *ChunkPipe[T]) unpack(c *chunk[T]) {
+	if c.packed == nil {
+		return
+	}
+	c.val = cl.load(c)
+	cl.comp.cache.drop(&c.packed[0])
+	c.packed = nil
+}
@@ -0,0 +1,98 @@
+package chunkpipe
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestCompression(t *testing.T) {
+	cp := NewChunkPipe[byte](WithCompression(Snappy, 64))
+	big := bytes.Repeat([]byte("chunkpipe"), 100)
+	cp.Push([]byte("small")).Push(big).Push(big)
+
+	st := cp.Stats()
+	if st.CompressedChunks != 2 {
+		t.Errorf("CompressedChunks = %d, want 2", st.CompressedChunks)
+	}
+	if st.CompressionRatio <= 1 {
+		t.Errorf("CompressionRatio = %v, want > 1", st.CompressionRatio)
+	}
+
+	t.Run("Get", func(t *testing.T) {
+		if v, ok := cp.Get(5 + 9); !ok || v != 'c' {
+			t.Errorf("Get(14) = %q, %v", v, ok)
+		}
+	})
+
+	t.Run("Iterators", func(t *testing.T) {
+		want := append(append([]byte("small"), big...), big...)
+		if got := cp.ValueSlice(); !bytes.Equal(got, want) {
+			t.Error("ValueSlice mismatch")
+		}
+		iter := cp.ChunkIter()
+		for iter.Next() {
+			if c := iter.V(); len(c) != 5 && !bytes.Equal(c, big) {
+				t.Error("ChunkIter mismatch")
+			}
+		}
+	})
+
+	t.Run("Pop", func(t *testing.T) {
+		if v, ok := cp.PopEnd(); !ok || v != 'e' {
+			t.Errorf("PopEnd = %q, %v", v, ok)
+		}
+		if c, ok := cp.PopChunkEnd(); !ok || !bytes.Equal(c, big[:len(big)-1]) {
+			t.Error("PopChunkEnd mismatch")
+		}
+		cp.PopChunkFront()
+		if c, ok := cp.PopChunkFront(); !ok || !bytes.Equal(c, big) {
+			t.Error("PopChunkFront mismatch")
+		}
+	})
+}
+
+// faultyCodec 用於測試異常的編解碼器
+type faultyCodec struct {
+	encode func(src []byte) []byte
+}
+
+func (faultyCodec) Name() string { return "faulty" }
+
+func (c faultyCodec) Encode(dst, src []byte) []byte { return c.encode(src) }
+
+func (faultyCodec) Decode(dst, src []byte) ([]byte, error) {
+	return nil, errors.New("corrupt")
+}
+
+func TestCompressionFaultyCodec(t *testing.T) {
+	t.Run("NotSmaller", func(t *testing.T) {
+		for _, enc := range []func([]byte) []byte{
+			func([]byte) []byte { return nil },
+			func(src []byte) []byte { return append([]byte{0}, src...) },
+		} {
+			cp := NewChunkPipe[byte](WithCompression(faultyCodec{enc}, 1))
+			cp.Push([]byte("hello"))
+			if st := cp.Stats(); st.CompressedChunks != 0 || st.RawBytes != 0 {
+				t.Errorf("Stats = %+v, want raw chunk", st)
+			}
+			if c, ok := cp.PopChunkFront(); !ok || string(c) != "hello" {
+				t.Errorf("PopChunkFront = %q, %v", c, ok)
+			}
+		}
+	})
+
+	t.Run("DecodeError", func(t *testing.T) {
+		cp := NewChunkPipe[byte](WithCompression(faultyCodec{func(src []byte) []byte { return src[:1] }}, 1))
+		cp.Push([]byte("hello"))
+		if v, ok := cp.Get(4); !ok || v != 0 {
+			t.Errorf("Get(4) = %q, %v", v, ok)
+		}
+		if c, ok := cp.PopChunkFront(); !ok || len(c) != 5 {
+			t.Errorf("PopChunkFront = %q, %v", c, ok)
+		}
+		if err := cp.DecodeErr(); err == nil || cp.Stats().DecodeErrors == 0 {
+			t.Errorf("DecodeErr = %v, DecodeErrors = %d", err, cp.Stats().DecodeErrors)
+		}
+	})
+}
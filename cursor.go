@@ -0,0 +1,188 @@
+package chunkpipe
+
+import (
+	"errors"
+	"slices"
+)
+
+var (
+	errCursorClosed   = errors.New("chunkpipe: cursor is closed")
+	errOffsetNotFound = errors.New("chunkpipe: offset is out of range")
+)
+
+// Cursor 是 ChunkPipe 上擁有獨立讀取位置的消費者，多個 Cursor 可以各自以自己的速度讀取同一串流。
+// 位置以串流偏移表示，從 ChunkPipe 建立以來插入的第一個元素為 0，不會因為頭部被取出而改變。
+// 頭部的塊只有在所有已註冊的 Cursor 都提交超過它之後才會被釋放。
+type Cursor[T any] struct {
+	pipe      *ChunkPipe[T]
+	pos       int
+	committed int
+	closed    bool
+}
+
+// NewCursor 註冊一個從目前頭部開始讀取的 Cursor
+func (cl *ChunkPipe[T]) NewCursor() *Cursor[T] {
+	cl.lock()
+	defer cl.mu.Unlock()
+
+	c := &Cursor[T]{
+		pipe:      cl,
+		pos:       cl.offset,
+		committed: cl.offset,
+	}
+	cl.cursors = append(cl.cursors, c)
+	return c
+}
+
+// Offset 返回下一次讀取的串流偏移
+func (c *Cursor[T]) Offset() int {
+	c.pipe.rlock()
+	defer c.pipe.mu.RUnlock()
+	return c.pos
+}
+
+// Committed 返回最近一次提交的串流偏移
+func (c *Cursor[T]) Committed() int {
+	c.pipe.rlock()
+	defer c.pipe.mu.RUnlock()
+	return c.committed
+}
+
+// Lag 返回尚未讀取的元素數
+func (c *Cursor[T]) Lag() int {
+	cl := c.pipe
+	cl.rlock()
+	defer cl.mu.RUnlock()
+	return max(0, cl.tail()-max(c.pos, cl.offset))
+}
+
+// Peek 返回從目前位置到所在塊結尾的視圖但不前進，沒有未讀數據時返回 false
+func (c *Cursor[T]) Peek() ([]T, bool) {
+	cl := c.pipe
+	cl.rlock()
+	defer cl.mu.RUnlock()
+
+	if c.closed {
+		return nil, false
+	}
+	return c.view()
+}
+
+// Next 返回從目前位置到所在塊結尾的視圖並前進，沒有未讀數據時返回 false
+func (c *Cursor[T]) Next() ([]T, bool) {
+	cl := c.pipe
+	cl.lock()
+	defer cl.mu.Unlock()
+
+	if c.closed {
+		return nil, false
+	}
+	val, ok := c.view()
+	if ok {
+		c.pos = max(c.pos, cl.offset) + len(val)
+	}
+	return val, ok
+}
+
+// Commit 確認目前位置之前的數據都已處理，所有 Cursor 都提交過的完整塊會從頭部釋放
+func (c *Cursor[T]) Commit() {
+	cl := c.pipe
+	var pe pendingEvent
+	defer pe.publish()
+	cl.lock()
+	defer cl.mu.Unlock()
+
+	if c.closed {
+		return
+	}
+	c.committed = c.pos
+	pe = cl.trimCommitted()
+}
+
+// Seek 將讀取位置移到串流偏移 offset，offset 必須介於目前的頭部與尾部之間
+func (c *Cursor[T]) Seek(offset int) error {
+	cl := c.pipe
+	cl.lock()
+	defer cl.mu.Unlock()
+
+	if c.closed {
+		return errCursorClosed
+	}
+	if offset < cl.offset || offset > cl.tail() {
+		return errOffsetNotFound
+	}
+	c.pos = offset
+	return nil
+}
+
+// Close 取消註冊 Cursor，不再阻止頭部的塊被釋放
+func (c *Cursor[T]) Close() error {
+	cl := c.pipe
+	var pe pendingEvent
+	defer pe.publish()
+	cl.lock()
+	defer cl.mu.Unlock()
+
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	if i := slices.Index(cl.cursors, c); i >= 0 {
+		cl.cursors = slices.Delete(cl.cursors, i, i+1)
+	}
+	pe = cl.trimCommitted()
+	return nil
+}
+
+// view 返回從目前位置到所在塊結尾的視圖，需持有鎖
+func (c *Cursor[T]) view() ([]T, bool) {
+	cl := c.pipe
+	// 頭部被其他操作取出時，從新的頭部繼續
+	index := max(c.pos, cl.offset) - cl.offset
+	if index >= cl.size() {
+		return nil, false
+	}
+	i := cl.locate(index)
+	val := cl.detach(&cl.list[i])
+	return val[index+cl.offset-cl.chunkStart(i):], true
+}
+
+// tail 返回尾部的串流偏移，需持有鎖
+func (cl *ChunkPipe[T]) tail() int {
+	if len(cl.list) == 0 {
+		return cl.offset
+	}
+	return cl.list[len(cl.list)-1].off
+}
+
+// clampCursors 在尾部的數據被移除後，將超出尾部的 Cursor 位置移回尾部，
+// 讓之後插入的數據不會被跳過。需持有寫鎖。
+func (cl *ChunkPipe[T]) clampCursors() {
+	tail := cl.tail()
+	for _, c := range cl.cursors {
+		c.pos = min(c.pos, tail)
+		c.committed = min(c.committed, tail)
+	}
+}
+
+// trimCommitted 釋放所有 Cursor 都已提交的完整塊，返回解鎖後要發布的事件，需持有寫鎖
+func (cl *ChunkPipe[T]) trimCommitted() pendingEvent {
+	if len(cl.cursors) == 0 {
+		return pendingEvent{}
+	}
+	low := cl.cursors[0].committed
+	for _, c := range cl.cursors[1:] {
+		low = min(low, c.committed)
+	}
+
+	cl.reclaim()
+	n := 0
+	for n < len(cl.list) && cl.list[n].off <= low {
+		n++
+	}
+	if n == 0 {
+		return pendingEvent{}
+	}
+	_, removed := cl.removeFront(cl.list[n-1].off-cl.offset, false)
+	return cl.event(EventPopChunkFront, removed)
+}
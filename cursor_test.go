@@ -0,0 +1,96 @@
+package chunkpipe
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCursor(t *testing.T) {
+	cp := NewChunkPipe[int]()
+	a := cp.NewCursor()
+	b := cp.NewCursor()
+	cp.Push([]int{1, 2, 3}).Push([]int{4}).Push([]int{5, 6})
+
+	if lag := a.Lag(); lag != 6 {
+		t.Errorf("Lag = %d, want 6", lag)
+	}
+	if c, ok := a.Peek(); !ok || !reflect.DeepEqual(c, []int{1, 2, 3}) {
+		t.Errorf("Peek = %v, %v", c, ok)
+	}
+
+	for i := 0; i < 2; i++ {
+		a.Next()
+	}
+	a.Commit()
+	if a.Offset() != 4 || a.Lag() != 2 {
+		t.Errorf("Offset = %d, Lag = %d", a.Offset(), a.Lag())
+	}
+	if cp.size() != 6 {
+		t.Errorf("chunks should be kept until every cursor commits, size = %d", cp.size())
+	}
+
+	if c, ok := b.Next(); !ok || len(c) != 3 {
+		t.Errorf("b.Next = %v, %v", c, ok)
+	}
+	b.Commit()
+	if cp.size() != 3 {
+		t.Errorf("size after both commits = %d, want 3", cp.size())
+	}
+
+	if err := b.Seek(5); err != nil {
+		t.Fatal(err)
+	}
+	if c, ok := b.Next(); !ok || !reflect.DeepEqual(c, []int{6}) {
+		t.Errorf("Next after Seek = %v, %v", c, ok)
+	}
+	if _, ok := b.Next(); ok {
+		t.Error("Next should return false at the tail")
+	}
+	if err := b.Seek(0); err == nil {
+		t.Error("Seek before the head should fail")
+	}
+
+	b.Commit()
+	a.Close()
+	if cp.size() != 0 {
+		t.Errorf("size after closing the slowest cursor = %d, want 0", cp.size())
+	}
+
+	cp.Push([]int{7})
+	if c, ok := b.Next(); !ok || c[0] != 7 {
+		t.Errorf("Next after Push = %v, %v", c, ok)
+	}
+	if _, ok := a.Next(); ok {
+		t.Error("closed cursor should not read")
+	}
+}
+
+func TestCursorAfterPopEnd(t *testing.T) {
+	for name, pop := range map[string]func(cp *ChunkPipe[int]){
+		"PopEndN":     func(cp *ChunkPipe[int]) { cp.PopEndN(3) },
+		"Truncate":    func(cp *ChunkPipe[int]) { cp.Truncate(0) },
+		"PopChunkEnd": func(cp *ChunkPipe[int]) { cp.PopChunkEnd() },
+		"PopEnd": func(cp *ChunkPipe[int]) {
+			cp.PopEnd()
+			cp.PopEnd()
+			cp.PopEnd()
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cp := NewChunkPipe[int]()
+			c := cp.NewCursor()
+			cp.Push([]int{1, 2, 3})
+			c.Next()
+			c.Commit()
+			pop(cp)
+			cp.Push([]int{4, 5, 6})
+
+			if lag := c.Lag(); lag != 3 {
+				t.Errorf("Lag = %d, want 3", lag)
+			}
+			if v, ok := c.Next(); !ok || len(v) != 3 || v[0] != 4 {
+				t.Errorf("Next = %v, %v", v, ok)
+			}
+		})
+	}
+}
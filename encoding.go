@@ -0,0 +1,198 @@
+package chunkpipe
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+)
+
+// MarshalBinary 的格式標記
+const (
+	binaryFormatBytes byte = 1 // ChunkPipe[byte]：逐塊的長度前綴與原始內容
+	binaryFormatGob   byte = 2 // 其他類型：以 gob 編碼的塊陣列
+)
+
+var errInvalidBinary = errors.New("chunkpipe: invalid binary encoding")
+
+// WithFlatJSON 讓 MarshalJSON 輸出扁平的元素陣列，而不是塊陣列的陣列。
+// 扁平格式不保留塊邊界，UnmarshalJSON 會將其還原為單一塊。
+func WithFlatJSON[T any]() Option[T] {
+	return func(cp *ChunkPipe[T]) {
+		cp.flatJSON = true
+	}
+}
+
+// chunks 返回所有塊內容的快照，需持有讀鎖
+func (cl *ChunkPipe[T]) chunks() [][]T {
+	list := cl.list
+	ret := make([][]T, len(list))
+	for i := range list {
+		ret[i] = cl.detach(&list[i])
+	}
+	return ret
+}
+
+// snapshot 在讀鎖下取得所有塊內容的快照
+func (cl *ChunkPipe[T]) snapshot() [][]T {
+	cl.rlock()
+	defer cl.mu.RUnlock()
+	return cl.chunks()
+}
+
+// replaceChunks 以 chunks 取代所有內容，新的塊與 Push 插入的塊一樣分配中繼資料，
+// 設定了 WithTTL 時從現在開始計算到期時間
+func (cl *ChunkPipe[T]) replaceChunks(chunks [][]T) {
+	cl.lock()
+	defer cl.mu.Unlock()
+
+	if cl.valueSlicePool.New == nil {
+		cl.initPools()
+	}
+	cl.clearChunks()
+	for _, c := range chunks {
+		if len(c) == 0 {
+			continue
+		}
+		var packed []byte
+		if cl.comp != nil {
+			packed = cl.packChunk(c)
+		}
+		cl.appendChunk(c, packed)
+		if cl.ttl > 0 {
+			last := &cl.list[len(cl.list)-1]
+			last.meta.Expires = last.meta.Enqueued.Add(cl.ttl)
+			cl.scheduleExpiry(last.meta.Expires)
+		}
+	}
+}
+
+// MarshalJSON 將 ChunkPipe 編碼為塊陣列的陣列，例如 [[1,2],[3]]
+func (cl *ChunkPipe[T]) MarshalJSON() ([]byte, error) {
+	cl.rlock()
+	defer cl.mu.RUnlock()
+
+	if cl.flatJSON {
+		ret := make([]T, 0, cl.size())
+		for i := range cl.list {
+			ret = append(ret, cl.load(&cl.list[i])...)
+		}
+		return json.Marshal(ret)
+	}
+	return json.Marshal(cl.chunks())
+}
+
+// UnmarshalJSON 以 JSON 內容取代 ChunkPipe 的數據，接受塊陣列或扁平的元素陣列
+func (cl *ChunkPipe[T]) UnmarshalJSON(data []byte) error {
+	var chunks [][]T
+	if err := json.Unmarshal(data, &chunks); err != nil {
+		var flat []T
+		if json.Unmarshal(data, &flat) != nil {
+			return err
+		}
+		chunks = [][]T{flat}
+	}
+	cl.replaceChunks(chunks)
+	return nil
+}
+
+// GobEncode 將 ChunkPipe 以 gob 編碼，保留塊邊界
+func (cl *ChunkPipe[T]) GobEncode() ([]byte, error) {
+	cl.rlock()
+	defer cl.mu.RUnlock()
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(cl.chunks()); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GobDecode 以 gob 內容取代 ChunkPipe 的數據
+func (cl *ChunkPipe[T]) GobDecode(data []byte) error {
+	var chunks [][]T
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&chunks); err != nil {
+		return err
+	}
+	cl.replaceChunks(chunks)
+	return nil
+}
+
+// MarshalBinary 將 ChunkPipe 編碼為二進位格式，保留塊邊界。
+// ChunkPipe[byte] 使用緊湊的長度前綴格式，其他類型使用 gob。
+func (cl *ChunkPipe[T]) MarshalBinary() ([]byte, error) {
+	chunks := cl.snapshot()
+
+	if bs, ok := any(chunks).([][]byte); ok {
+		size := 1 + binary.MaxVarintLen64
+		for _, c := range bs {
+			size += binary.MaxVarintLen64 + len(c)
+		}
+		buf := make([]byte, 0, size)
+		buf = append(buf, binaryFormatBytes)
+		buf = binary.AppendUvarint(buf, uint64(len(bs)))
+		for _, c := range bs {
+			buf = binary.AppendUvarint(buf, uint64(len(c)))
+			buf = append(buf, c...)
+		}
+		return buf, nil
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte(binaryFormatGob)
+	if err := gob.NewEncoder(&buf).Encode(chunks); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary 以 MarshalBinary 產生的內容取代 ChunkPipe 的數據
+func (cl *ChunkPipe[T]) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 {
+		return errInvalidBinary
+	}
+
+	var chunks [][]T
+	switch data[0] {
+	case binaryFormatBytes:
+		bs, err := decodeByteChunks(data[1:])
+		if err != nil {
+			return err
+		}
+		var ok bool
+		if chunks, ok = any(bs).([][]T); !ok {
+			return errInvalidBinary
+		}
+	case binaryFormatGob:
+		if err := gob.NewDecoder(bytes.NewReader(data[1:])).Decode(&chunks); err != nil {
+			return err
+		}
+	default:
+		return errInvalidBinary
+	}
+	cl.replaceChunks(chunks)
+	return nil
+}
+
+func decodeByteChunks(data []byte) ([][]byte, error) {
+	n, k := binary.Uvarint(data)
+	if k <= 0 || n > uint64(len(data)) {
+		return nil, errInvalidBinary
+	}
+	data = data[k:]
+
+	ret := make([][]byte, 0, n)
+	for i := uint64(0); i < n; i++ {
+		size, k := binary.Uvarint(data)
+		if k <= 0 || size > uint64(len(data)-k) {
+			return nil, errInvalidBinary
+		}
+		data = data[k:]
+		c := make([]byte, size)
+		copy(c, data)
+		ret = append(ret, c)
+		data = data[size:]
+	}
+	return ret, nil
+}
@@ -0,0 +1,105 @@
+package chunkpipe
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestEncoding(t *testing.T) {
+	want := [][]int{{1, 2}, {3}, {4, 5, 6}}
+	newPipe := func() *ChunkPipe[int] {
+		cp := NewChunkPipe[int]()
+		for _, c := range want {
+			cp.Push(c)
+		}
+		return cp
+	}
+
+	t.Run("JSON", func(t *testing.T) {
+		type wrapper struct {
+			Pipe *ChunkPipe[int]
+		}
+		data, err := json.Marshal(wrapper{Pipe: newPipe()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != `{"Pipe":[[1,2],[3],[4,5,6]]}` {
+			t.Errorf("MarshalJSON = %s", data)
+		}
+
+		var got wrapper
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatal(err)
+		}
+		if chunks := got.Pipe.ChunkSlice(); !reflect.DeepEqual(chunks, want) {
+			t.Errorf("UnmarshalJSON = %v, want %v", chunks, want)
+		}
+	})
+
+	t.Run("FlatJSON", func(t *testing.T) {
+		cp := NewChunkPipe[int](WithFlatJSON[int]())
+		cp.Push([]int{1, 2}).Push([]int{3})
+		data, _ := json.Marshal(cp)
+		if string(data) != `[1,2,3]` {
+			t.Errorf("MarshalJSON = %s", data)
+		}
+		if err := cp.UnmarshalJSON(data); err != nil {
+			t.Fatal(err)
+		}
+		if chunks := cp.ChunkSlice(); !reflect.DeepEqual(chunks, [][]int{{1, 2, 3}}) {
+			t.Errorf("UnmarshalJSON = %v", chunks)
+		}
+	})
+
+	t.Run("Gob", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(newPipe()); err != nil {
+			t.Fatal(err)
+		}
+		got := NewChunkPipe[int]()
+		got.Push([]int{9})
+		if err := gob.NewDecoder(&buf).Decode(got); err != nil {
+			t.Fatal(err)
+		}
+		if chunks := got.ChunkSlice(); !reflect.DeepEqual(chunks, want) {
+			t.Errorf("GobDecode = %v, want %v", chunks, want)
+		}
+		if v, ok := got.Get(3); !ok || v != 4 {
+			t.Errorf("Get(3) = %v, %v", v, ok)
+		}
+	})
+
+	t.Run("Binary", func(t *testing.T) {
+		data, err := newPipe().MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := NewChunkPipe[int]()
+		if err := got.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		if chunks := got.ChunkSlice(); !reflect.DeepEqual(chunks, want) {
+			t.Errorf("UnmarshalBinary = %v, want %v", chunks, want)
+		}
+
+		bp := NewChunkPipe[byte]()
+		bp.Push([]byte("ab")).Push([]byte("cde"))
+		data, _ = bp.MarshalBinary()
+		if data[0] != binaryFormatBytes {
+			t.Errorf("format = %d, want %d", data[0], binaryFormatBytes)
+		}
+		var got2 ChunkPipe[byte]
+		if err := got2.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		if chunks := got2.ChunkSlice(); len(chunks) != 2 || string(chunks[1]) != "cde" {
+			t.Errorf("UnmarshalBinary = %q", chunks)
+		}
+		if err := got2.UnmarshalBinary(data[:4]); err == nil {
+			t.Error("expected error for truncated data")
+		}
+	})
+}
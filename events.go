@@ -0,0 +1,292 @@
+package chunkpipe
+
+import (
+	"slices"
+	"sync"
+)
+
+// EventKind 是事件類型，可以用位元或組合成訂閱的篩選條件
+type EventKind uint
+
+const (
+	EventPush EventKind = 1 << iota
+	EventPopFront
+	EventPopChunkFront
+	EventPopEnd
+	EventPopChunkEnd
+	EventClear
+	// EventExpire 在過期的塊被移除時發出
+	EventExpire
+
+	// EventAll 訂閱所有事件
+	EventAll = EventPush | EventPopFront | EventPopChunkFront | EventPopEnd | EventPopChunkEnd | EventClear | EventExpire
+)
+
+// Event 描述一次改變 ChunkPipe 內容的操作
+type Event struct {
+	Kind EventKind
+	// 受影響的元素數
+	Count int
+	// 操作完成後的元素數
+	Len int
+	// DropEvents 策略下，在此事件之前被丟棄的事件數
+	Dropped int
+	// CoalesceEvents 策略下，合併進此事件的事件數，未合併時為 1
+	Coalesced int
+}
+
+// SlowPolicy 決定訂閱者的緩衝區已滿時如何處理新事件
+type SlowPolicy int
+
+const (
+	// DropEvents 丟棄新事件，並在下一個送出的事件中記錄丟棄數
+	DropEvents SlowPolicy = iota
+	// BlockProducer 讓產生事件的呼叫在解鎖後等待訂閱者，會拖慢生產者但不會阻塞其他操作
+	BlockProducer
+	// CoalesceEvents 將連續的同類型事件合併，Count 相加、Len 取最新值
+	CoalesceEvents
+)
+
+// 預設的訂閱緩衝區大小
+const defaultEventBuffer = 64
+
+// SubscribeOption 調整訂閱的行為
+type SubscribeOption func(*subscriber)
+
+// WithSlowPolicy 設定緩衝區已滿時的處理策略，預設為 DropEvents
+func WithSlowPolicy(policy SlowPolicy) SubscribeOption {
+	return func(s *subscriber) {
+		s.policy = policy
+	}
+}
+
+// WithEventBuffer 設定訂閱 channel 的緩衝區大小
+func WithEventBuffer(size int) SubscribeOption {
+	return func(s *subscriber) {
+		s.buffer = size
+	}
+}
+
+type subscriber struct {
+	filter EventKind
+	policy SlowPolicy
+	buffer int
+	ch     chan Event
+	done   chan struct{}
+
+	mu      sync.Mutex
+	wg      sync.WaitGroup
+	closed  bool
+	dropped int
+	// CoalesceEvents 策略下等待送出的事件，相鄰的事件類型不同
+	pending []Event
+	// 有事件等待送出或正在送出，新事件必須排在後面
+	busy bool
+	kick chan struct{}
+}
+
+// pendingEvent 是在鎖內建立、解鎖後才發布的事件
+type pendingEvent struct {
+	subs []*subscriber
+	ev   Event
+	// 發布事件後呼叫的 After 鉤子，未安裝鉤子時為 nil
+	after func()
+}
+
+// Subscribe 訂閱符合 filter 的事件，返回事件 channel 與取消訂閱的函式。
+// 事件在操作解鎖後送出，並發操作的事件順序不保證與操作完成的順序相同。
+func (cl *ChunkPipe[T]) Subscribe(filter EventKind, opts ...SubscribeOption) (<-chan Event, func()) {
+	s := &subscriber{
+		filter: filter,
+		buffer: defaultEventBuffer,
+		done:   make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.ch = make(chan Event, s.buffer)
+	if s.policy == CoalesceEvents {
+		s.kick = make(chan struct{}, 1)
+		s.wg.Add(1)
+		go s.deliver()
+	}
+
+	cl.lock()
+	// 複製後寫入，讓發布者可以在解鎖後安全地走訪
+	cl.subs = append(slices.Clip(cl.subs), s)
+	cl.mu.Unlock()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			cl.lock()
+			if i := slices.Index(cl.subs, s); i >= 0 {
+				cl.subs = slices.Delete(slices.Clone(cl.subs), i, i+1)
+			}
+			cl.mu.Unlock()
+			s.close()
+		})
+	}
+	return s.ch, cancel
+}
+
+// Clear 移除所有數據
+func (cl *ChunkPipe[T]) Clear() {
+	var pe pendingEvent
+	defer pe.publish()
+	cl.lock()
+	defer cl.mu.Unlock()
+
+	n := cl.size()
+	cl.clearChunks()
+	pe = cl.event(EventClear, n)
+}
+
+// event 記錄指標，並在有訂閱者時建立待發布的事件，需持有鎖
+func (cl *ChunkPipe[T]) event(kind EventKind, count int) pendingEvent {
+	if count == 0 {
+		return pendingEvent{}
+	}
+	if cl.metrics != nil {
+		cl.metrics.record(kind, count)
+	}
+	return cl.pending(kind, count)
+}
+
+// requeueEvent 記錄數據被放回頭部，並建立待發布的插入事件，需持有鎖
+func (cl *ChunkPipe[T]) requeueEvent(count int) pendingEvent {
+	if count == 0 {
+		return pendingEvent{}
+	}
+	if cl.metrics != nil {
+		cl.metrics.recordRequeue(count)
+	}
+	return cl.pending(EventPush, count)
+}
+
+// pending 在有訂閱者時建立待發布的事件，需持有鎖
+func (cl *ChunkPipe[T]) pending(kind EventKind, count int) pendingEvent {
+	if len(cl.subs) == 0 {
+		return pendingEvent{}
+	}
+	return pendingEvent{
+		subs: cl.subs,
+		ev: Event{
+			Kind:  kind,
+			Count: count,
+			Len:   cl.size(),
+		},
+	}
+}
+
+// publish 將事件送給訂閱者，必須在解鎖後呼叫
+func (pe *pendingEvent) publish() {
+	for _, s := range pe.subs {
+		if s.filter&pe.ev.Kind != 0 {
+			s.send(pe.ev)
+		}
+	}
+	if pe.after != nil {
+		pe.after()
+	}
+}
+
+func (s *subscriber) send(ev Event) {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+
+	switch s.policy {
+	case BlockProducer:
+		s.wg.Add(1)
+		s.mu.Unlock()
+		defer s.wg.Done()
+		ev.Coalesced = 1
+		select {
+		case s.ch <- ev:
+		case <-s.done:
+		}
+		return
+
+	case CoalesceEvents:
+		defer s.mu.Unlock()
+		if !s.busy {
+			ev.Coalesced = 1
+			select {
+			case s.ch <- ev:
+				return
+			default:
+			}
+		}
+		// 只合併進最後一個等待的事件，保持事件順序與最新的 Len
+		if n := len(s.pending); n > 0 {
+			if p := &s.pending[n-1]; p.Kind == ev.Kind {
+				p.Count += ev.Count
+				p.Len = ev.Len
+				p.Coalesced++
+				return
+			}
+		}
+		if ev.Coalesced == 0 {
+			ev.Coalesced = 1
+		}
+		s.pending = append(s.pending, ev)
+		s.busy = true
+		select {
+		case s.kick <- struct{}{}:
+		default:
+		}
+
+	default:
+		defer s.mu.Unlock()
+		ev.Dropped = s.dropped
+		ev.Coalesced = 1
+		select {
+		case s.ch <- ev:
+			s.dropped = 0
+		default:
+			s.dropped++
+		}
+	}
+}
+
+// deliver 在 CoalesceEvents 策略下依序送出合併後的事件
+func (s *subscriber) deliver() {
+	defer s.wg.Done()
+	for {
+		select {
+		case <-s.kick:
+		case <-s.done:
+			return
+		}
+		for {
+			s.mu.Lock()
+			if len(s.pending) == 0 {
+				s.busy = false
+				s.mu.Unlock()
+				break
+			}
+			ev := s.pending[0]
+			s.pending = s.pending[1:]
+			s.mu.Unlock()
+
+			select {
+			case s.ch <- ev:
+			case <-s.done:
+				return
+			}
+		}
+	}
+}
+
+func (s *subscriber) close() {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+
+	close(s.done)
+	s.wg.Wait()
+	close(s.ch)
+}
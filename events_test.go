@@ -0,0 +1,185 @@
+package chunkpipe
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSubscribe(t *testing.T) {
+	t.Run("Events", func(t *testing.T) {
+		cp := NewChunkPipe[int]()
+		ch, cancel := cp.Subscribe(EventAll)
+
+		cp.Push([]int{1, 2, 3}).Push([]int{4, 5})
+		cp.PopFront()
+		cp.PopChunkFront()
+		cp.PopEnd()
+		cp.PopChunkEnd()
+		cp.Push([]int{6})
+		cp.Clear()
+		cancel()
+
+		want := []Event{
+			{Kind: EventPush, Count: 3, Len: 3},
+			{Kind: EventPush, Count: 2, Len: 5},
+			{Kind: EventPopFront, Count: 1, Len: 4},
+			{Kind: EventPopChunkFront, Count: 2, Len: 2},
+			{Kind: EventPopEnd, Count: 1, Len: 1},
+			{Kind: EventPopChunkEnd, Count: 1, Len: 0},
+			{Kind: EventPush, Count: 1, Len: 1},
+			{Kind: EventClear, Count: 1, Len: 0},
+		}
+		i := 0
+		for ev := range ch {
+			if i >= len(want) {
+				t.Fatalf("unexpected event %+v", ev)
+			}
+			w := want[i]
+			if ev.Kind != w.Kind || ev.Count != w.Count || ev.Len != w.Len {
+				t.Errorf("event %d = %+v, want %+v", i, ev, w)
+			}
+			i++
+		}
+		if i != len(want) {
+			t.Errorf("got %d events, want %d", i, len(want))
+		}
+	})
+
+	t.Run("Filter", func(t *testing.T) {
+		cp := NewChunkPipe[int]()
+		ch, cancel := cp.Subscribe(EventPopFront | EventPopEnd)
+		defer cancel()
+		cp.Push([]int{1, 2})
+		cp.PopEnd()
+		if ev := <-ch; ev.Kind != EventPopEnd {
+			t.Errorf("event = %+v, want PopEnd", ev)
+		}
+	})
+
+	t.Run("Drop", func(t *testing.T) {
+		cp := NewChunkPipe[int]()
+		ch, cancel := cp.Subscribe(EventPush, WithEventBuffer(1))
+		defer cancel()
+		for i := 0; i < 5; i++ {
+			cp.Push([]int{i})
+		}
+		<-ch
+		cp.Push([]int{5})
+		if ev := <-ch; ev.Dropped != 4 || ev.Len != 6 {
+			t.Errorf("event after drops = %+v", ev)
+		}
+	})
+
+	t.Run("Coalesce", func(t *testing.T) {
+		cp := NewChunkPipe[int]()
+		ch, cancel := cp.Subscribe(EventAll, WithSlowPolicy(CoalesceEvents), WithEventBuffer(1))
+		defer cancel()
+		for i := 0; i < 5; i++ {
+			cp.Push([]int{i, i})
+		}
+		cp.PopFront()
+
+		first := <-ch
+		pushes := <-ch
+		pops := <-ch
+		if first.Count != 2 || pushes.Kind != EventPush || pushes.Count != 8 || pushes.Coalesced != 4 || pushes.Len != 10 {
+			t.Errorf("coalesced pushes = %+v, %+v", first, pushes)
+		}
+		if pops.Kind != EventPopFront || pops.Len != 9 {
+			t.Errorf("pop event = %+v", pops)
+		}
+	})
+
+	t.Run("CoalesceKeepsOrder", func(t *testing.T) {
+		cp := NewChunkPipe[int]()
+		ch, cancel := cp.Subscribe(EventAll, WithSlowPolicy(CoalesceEvents), WithEventBuffer(0))
+		defer cancel()
+		cp.Push([]int{1})
+		cp.PopFront()
+		cp.Push([]int{2})
+
+		var got []EventKind
+		var last Event
+		for len(got) < 3 {
+			select {
+			case last = <-ch:
+				got = append(got, last.Kind)
+			case <-time.After(time.Second):
+				t.Fatalf("events = %v", got)
+			}
+		}
+		if want := []EventKind{EventPush, EventPopFront, EventPush}; !reflect.DeepEqual(got, want) {
+			t.Errorf("kinds = %v, want %v", got, want)
+		}
+		if last.Len != cp.Stats().Len {
+			t.Errorf("last Len = %d, want %d", last.Len, cp.Stats().Len)
+		}
+	})
+
+	t.Run("Block", func(t *testing.T) {
+		cp := NewChunkPipe[int]()
+		ch, cancel := cp.Subscribe(EventPush, WithSlowPolicy(BlockProducer), WithEventBuffer(0))
+		done := make(chan struct{})
+		go func() {
+			cp.Push([]int{1})
+			close(done)
+		}()
+		select {
+		case <-done:
+			t.Error("Push should wait for a blocking subscriber")
+		case <-time.After(10 * time.Millisecond):
+		}
+		if v, ok := cp.Get(0); !ok || v != 1 {
+			t.Error("other operations should not be blocked")
+		}
+		<-ch
+		<-done
+		cancel()
+	})
+}
+
+func TestEventsFromOtherRemovals(t *testing.T) {
+	cp := NewChunkPipe[byte]()
+	ch, cancel := cp.Subscribe(EventAll, WithEventBuffer(32))
+	cp.Push([]byte("ab\ncd")).Push([]byte("efg")).Push([]byte("h"))
+
+	ReadLine(cp)
+	ReadRune(cp)
+	UnreadRune(cp)
+	id, _, _ := cp.Lease(context.Background(), time.Minute)
+	cp.Nack(id)
+	id, _, _ = cp.Lease(context.Background(), time.Minute)
+	cp.Ack(id)
+	cur := cp.NewCursor()
+	cur.Next()
+	cur.Commit()
+	cancel()
+
+	want := []Event{
+		{Kind: EventPush, Count: 5, Len: 5},
+		{Kind: EventPush, Count: 3, Len: 8},
+		{Kind: EventPush, Count: 1, Len: 9},
+		{Kind: EventPopFront, Count: 3, Len: 6},
+		{Kind: EventPopFront, Count: 1, Len: 5},
+		{Kind: EventPush, Count: 1, Len: 6},
+		{Kind: EventPopChunkFront, Count: 1, Len: 5},
+		{Kind: EventPush, Count: 1, Len: 6},
+		{Kind: EventPopChunkFront, Count: 1, Len: 5},
+		{Kind: EventPopChunkFront, Count: 1, Len: 4},
+	}
+	var got []Event
+	for ev := range ch {
+		got = append(got, Event{Kind: ev.Kind, Count: ev.Count, Len: ev.Len})
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("events =\n%v\nwant\n%v", got, want)
+	}
+
+	st := cp.Stats()
+	if int(st.ElementsIn+st.ElementsRequeued-st.ElementsOut) != st.Len {
+		t.Errorf("ElementsIn %d + ElementsRequeued %d - ElementsOut %d != Len %d",
+			st.ElementsIn, st.ElementsRequeued, st.ElementsOut, st.Len)
+	}
+}
@@ -0,0 +1,80 @@
+package chunkpipe
+
+import "errors"
+
+// ErrClosed 表示 ChunkPipe 已關閉
+var ErrClosed = errors.New("chunkpipe: pipe is closed")
+
+// Hooks 是插入與彈出時呼叫的回呼函式，未設定的欄位會被略過。
+// 所有鉤子都在不持有 ChunkPipe 任何鎖的情況下呼叫，因此可以在鉤子中呼叫 ChunkPipe 的方法，
+// 但 Before 與 After 之間其他 goroutine 仍可能修改內容。
+// 鉤子只作用於 Push、TryPush 與 Pop 系列方法，Discard、Truncate 與 Clear 不會觸發。
+type Hooks[T any] struct {
+	// BeforePush 在加鎖與壓縮之前呼叫，返回的切片取代原本要插入的數據，
+	// 返回錯誤則拒絕插入；返回空切片時不插入任何數據
+	BeforePush func(data []T) ([]T, error)
+	// AfterPush 在插入完成並解鎖後呼叫，data 是實際插入的數據
+	AfterPush func(data []T)
+	// BeforePop 在加鎖之前呼叫，返回錯誤時該次彈出不取出任何數據
+	BeforePop func(kind EventKind) error
+	// AfterPop 在彈出完成並解鎖後呼叫，chunks 是被彈出數據的塊視圖
+	AfterPop func(kind EventKind, chunks [][]T)
+}
+
+// WithHooks 安裝一組鉤子，多次使用時按安裝順序串接：
+// BeforePush 依序轉換數據，任一鉤子返回錯誤即停止；其餘鉤子依序呼叫。
+// 未安裝任何鉤子時插入與彈出不會有額外開銷。
+func WithHooks[T any](h Hooks[T]) Option[T] {
+	return func(cp *ChunkPipe[T]) {
+		cp.hooks = append(cp.hooks, h)
+	}
+}
+
+// TryPush 插入數據，被 BeforePush 拒絕時返回其錯誤，ChunkPipe 已關閉時返回 ErrClosed
+func (cl *ChunkPipe[T]) TryPush(data []T) error {
+	_, err := cl.push(data, nil, cl.ttl)
+	return err
+}
+
+func (cl *ChunkPipe[T]) beforePush(data []T) ([]T, error) {
+	for _, h := range cl.hooks {
+		if h.BeforePush == nil {
+			continue
+		}
+		var err error
+		if data, err = h.BeforePush(data); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+func (cl *ChunkPipe[T]) afterPush(data []T) {
+	for _, h := range cl.hooks {
+		if h.AfterPush != nil {
+			h.AfterPush(data)
+		}
+	}
+}
+
+// beforePop 依序呼叫 BeforePop，返回 false 表示彈出被拒絕
+func (cl *ChunkPipe[T]) beforePop(kind EventKind) bool {
+	for _, h := range cl.hooks {
+		if h.BeforePop != nil && h.BeforePop(kind) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// afterPop 返回依序呼叫 AfterPop 的函式，由 pendingEvent 在解鎖後執行
+func (cl *ChunkPipe[T]) afterPop(kind EventKind, chunks [][]T) func() {
+	hooks := cl.hooks
+	return func() {
+		for _, h := range hooks {
+			if h.AfterPop != nil {
+				h.AfterPop(kind, chunks)
+			}
+		}
+	}
+}
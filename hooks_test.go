@@ -0,0 +1,126 @@
+package chunkpipe
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestHooks(t *testing.T) {
+	t.Run("Chain", func(t *testing.T) {
+		errTooLong := errors.New("too long")
+		var pushed [][]int
+		var popped []EventKind
+		cp := NewChunkPipe[int](
+			WithHooks(Hooks[int]{
+				BeforePush: func(data []int) ([]int, error) {
+					if len(data) > 3 {
+						return nil, errTooLong
+					}
+					return data, nil
+				},
+			}),
+			WithHooks(Hooks[int]{
+				BeforePush: func(data []int) ([]int, error) {
+					out := make([]int, len(data))
+					for i, v := range data {
+						out[i] = v * 10
+					}
+					return out, nil
+				},
+				AfterPush: func(data []int) {
+					pushed = append(pushed, data)
+				},
+				AfterPop: func(kind EventKind, chunks [][]int) {
+					popped = append(popped, kind)
+				},
+			}),
+		)
+
+		if err := cp.TryPush([]int{1, 2, 3, 4}); err != errTooLong {
+			t.Errorf("TryPush = %v, want %v", err, errTooLong)
+		}
+		cp.Push([]int{1, 2}).Push([]int{3})
+		if !reflect.DeepEqual(pushed, [][]int{{10, 20}, {30}}) {
+			t.Errorf("AfterPush saw %v", pushed)
+		}
+		if got := cp.ValueSlice(); !reflect.DeepEqual(got, []int{10, 20, 30}) {
+			t.Errorf("ValueSlice = %v", got)
+		}
+
+		cp.PopFront()
+		cp.PopChunkEnd()
+		cp.PopFrontN(5)
+		want := []EventKind{EventPopFront, EventPopChunkEnd, EventPopFront}
+		if !reflect.DeepEqual(popped, want) {
+			t.Errorf("AfterPop saw %v, want %v", popped, want)
+		}
+	})
+
+	t.Run("AfterPopData", func(t *testing.T) {
+		var got [][]int
+		cp := NewChunkPipe[int](WithHooks(Hooks[int]{
+			AfterPop: func(kind EventKind, chunks [][]int) {
+				for _, c := range chunks {
+					got = append(got, append([]int(nil), c...))
+				}
+			},
+		}))
+		cp.Push([]int{1, 2, 3}).Push([]int{4, 5})
+		cp.PopEnd()
+		cp.PopEndN(2)
+		dst := make([]int, 2)
+		cp.PopFrontInto(dst)
+		want := [][]int{{5}, {3}, {4}, {1, 2}}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("AfterPop chunks = %v, want %v", got, want)
+		}
+		// 空的 ChunkPipe 不會觸發 AfterPop
+		cp.PopFront()
+		cp.PopFrontN(1)
+		if len(got) != len(want) {
+			t.Errorf("AfterPop called on empty pipe")
+		}
+	})
+
+	t.Run("RejectPop", func(t *testing.T) {
+		allow := false
+		cp := NewChunkPipe[int](WithHooks(Hooks[int]{
+			BeforePop: func(kind EventKind) error {
+				if !allow {
+					return errors.New("paused")
+				}
+				return nil
+			},
+		}))
+		cp.Push([]int{1, 2})
+		if _, ok := cp.PopFront(); ok {
+			t.Error("PopFront should be rejected")
+		}
+		if _, ok := cp.PopChunkFront(); ok {
+			t.Error("PopChunkFront should be rejected")
+		}
+		if cp.Stats().Len != 2 {
+			t.Errorf("Len = %d, want 2", cp.Stats().Len)
+		}
+		allow = true
+		if v, ok := cp.PopFront(); !ok || v != 1 {
+			t.Errorf("PopFront = %v, %v", v, ok)
+		}
+	})
+
+	t.Run("ReentrantAndClosed", func(t *testing.T) {
+		var cp *ChunkPipe[int]
+		cp = NewChunkPipe[int](WithHooks(Hooks[int]{
+			AfterPush: func(data []int) {
+				// 鉤子不持有鎖，可以呼叫 ChunkPipe 的方法
+				_ = cp.Stats()
+			},
+		}))
+		cp.Push([]int{1})
+		cp.Close()
+		if err := cp.TryPush([]int{2}); err != ErrClosed {
+			t.Errorf("TryPush after Close = %v, want ErrClosed", err)
+		}
+	})
+}
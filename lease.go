@@ -0,0 +1,196 @@
+package chunkpipe
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// ErrLeaseNotFound 表示租約不存在，可能已被確認、退回或逾時
+var ErrLeaseNotFound = errors.New("chunkpipe: lease not found")
+
+// LeaseID 識別一個租約
+type LeaseID uint64
+
+// leaseTable 保存租用中的塊，由 ChunkPipe.mu 保護
+type leaseTable[T any] struct {
+	next     LeaseID
+	inflight map[LeaseID]*lease[T]
+}
+
+type lease[T any] struct {
+	val      []T
+	attempts int
+	meta     ChunkMeta
+	timer    *time.Timer
+}
+
+// WithDeadLetter 設定死信 ChunkPipe：塊被租用 maxAttempts 次後仍被退回或逾時，
+// 會被插入 dlq 而不是放回頭部
+func WithDeadLetter[T any](dlq *ChunkPipe[T], maxAttempts int) Option[T] {
+	return func(cp *ChunkPipe[T]) {
+		cp.deadLetter = dlq
+		cp.maxAttempts = maxAttempts
+	}
+}
+
+// Lease 取出頭部的塊並租用 timeout，ChunkPipe 為空時等待新數據。
+// 租約在 timeout 內沒有被 Ack 時，塊會被放回頭部重新投遞。
+// ChunkPipe 已關閉、為空且沒有租用中的塊時返回 io.EOF。
+func (cl *ChunkPipe[T]) Lease(ctx context.Context, timeout time.Duration) (LeaseID, []T, error) {
+	for {
+		id, val, wait := cl.tryLease(timeout)
+		if wait == nil {
+			if val == nil {
+				return 0, nil, io.EOF
+			}
+			return id, val, nil
+		}
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return 0, nil, ctx.Err()
+		}
+	}
+}
+
+// Ack 確認租約已處理完成
+func (cl *ChunkPipe[T]) Ack(id LeaseID) error {
+	cl.lock()
+	defer cl.mu.Unlock()
+
+	l := cl.takeLease(id)
+	if l == nil {
+		return ErrLeaseNotFound
+	}
+	if cl.closed && len(cl.leases.inflight) == 0 {
+		// 喚醒在已關閉的 ChunkPipe 上等待最後一個租約的 Lease，讓它返回 io.EOF
+		cl.signal()
+	}
+	return nil
+}
+
+// Nack 退回租約，塊會被放回頭部或移到死信 ChunkPipe
+func (cl *ChunkPipe[T]) Nack(id LeaseID) error {
+	cl.lock()
+	l := cl.takeLease(id)
+	if l == nil {
+		cl.mu.Unlock()
+		return ErrLeaseNotFound
+	}
+	pe, dead := cl.requeue(l)
+	cl.mu.Unlock()
+	pe.publish()
+
+	if dead {
+		cl.deadLetter.Push(l.val)
+	}
+	return nil
+}
+
+// Attempts 返回租約中的塊已被投遞的次數，租約不存在時返回 0
+func (cl *ChunkPipe[T]) Attempts(id LeaseID) int {
+	cl.rlock()
+	defer cl.mu.RUnlock()
+
+	if cl.leases == nil {
+		return 0
+	}
+	if l := cl.leases.inflight[id]; l != nil {
+		return l.attempts
+	}
+	return 0
+}
+
+// InFlight 返回租用中的塊數
+func (cl *ChunkPipe[T]) InFlight() int {
+	cl.rlock()
+	defer cl.mu.RUnlock()
+
+	if cl.leases == nil {
+		return 0
+	}
+	return len(cl.leases.inflight)
+}
+
+// tryLease 取出並租用頭部的塊；需要等待時返回等待用的 channel
+func (cl *ChunkPipe[T]) tryLease(timeout time.Duration) (LeaseID, []T, <-chan struct{}) {
+	var pe pendingEvent
+	defer pe.publish()
+	cl.lock()
+	defer cl.mu.Unlock()
+	cl.reclaim()
+
+	if cl.leases == nil {
+		cl.leases = &leaseTable[T]{inflight: make(map[LeaseID]*lease[T])}
+	}
+	if len(cl.list) == 0 {
+		if cl.closed && len(cl.leases.inflight) == 0 {
+			return 0, nil, nil
+		}
+		// 等待新數據或被退回的塊
+		return 0, nil, cl.waitChan()
+	}
+
+	c := &cl.list[0]
+	val := cl.detach(c)
+	l := &lease[T]{
+		val:      val,
+		attempts: c.attempts + 1,
+		meta:     c.meta,
+	}
+	_, n := cl.removeFront(c.off-cl.offset, false)
+	pe = cl.event(EventPopChunkFront, n)
+
+	cl.leases.next++
+	id := cl.leases.next
+	cl.leases.inflight[id] = l
+	l.timer = time.AfterFunc(timeout, func() {
+		cl.expireLease(id)
+	})
+	return id, val, nil
+}
+
+// takeLease 從租用表移除並返回租約，需持有寫鎖
+func (cl *ChunkPipe[T]) takeLease(id LeaseID) *lease[T] {
+	if cl.leases == nil {
+		return nil
+	}
+	l := cl.leases.inflight[id]
+	if l == nil {
+		return nil
+	}
+	delete(cl.leases.inflight, id)
+	l.timer.Stop()
+	return l
+}
+
+// requeue 將租約的塊放回頭部並返回插入事件，超過最大租用次數時返回 true，
+// 由呼叫者在解鎖後發布事件並插入死信 ChunkPipe。需持有寫鎖。
+func (cl *ChunkPipe[T]) requeue(l *lease[T]) (pendingEvent, bool) {
+	if cl.deadLetter != nil && l.attempts >= cl.maxAttempts {
+		// 喚醒在已關閉的 ChunkPipe 上等待退回塊的 Lease
+		cl.signal()
+		return pendingEvent{}, true
+	}
+	pe := cl.insertFront(l.val, l.meta)
+	cl.list[0].attempts = l.attempts
+	return pe, false
+}
+
+func (cl *ChunkPipe[T]) expireLease(id LeaseID) {
+	cl.lock()
+	l := cl.takeLease(id)
+	if l == nil {
+		cl.mu.Unlock()
+		return
+	}
+	pe, dead := cl.requeue(l)
+	cl.mu.Unlock()
+	pe.publish()
+
+	if dead {
+		cl.deadLetter.Push(l.val)
+	}
+}
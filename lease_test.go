@@ -0,0 +1,102 @@
+package chunkpipe
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLease(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("AckNack", func(t *testing.T) {
+		cp := NewChunkPipe[int]()
+		cp.Push([]int{1, 2}).Push([]int{3})
+
+		id, val, err := cp.Lease(ctx, time.Minute)
+		if err != nil || !reflect.DeepEqual(val, []int{1, 2}) {
+			t.Fatalf("Lease = %v, %v", val, err)
+		}
+		if cp.InFlight() != 1 || cp.Attempts(id) != 1 {
+			t.Errorf("InFlight = %d, Attempts = %d", cp.InFlight(), cp.Attempts(id))
+		}
+		if err := cp.Nack(id); err != nil {
+			t.Fatal(err)
+		}
+		if err := cp.Ack(id); err != ErrLeaseNotFound {
+			t.Errorf("Ack after Nack = %v", err)
+		}
+
+		id, val, _ = cp.Lease(ctx, time.Minute)
+		if !reflect.DeepEqual(val, []int{1, 2}) || cp.Attempts(id) != 2 {
+			t.Errorf("redelivered = %v, attempts = %d", val, cp.Attempts(id))
+		}
+		if err := cp.Ack(id); err != nil {
+			t.Fatal(err)
+		}
+		if got := cp.ValueSlice(); !reflect.DeepEqual(got, []int{3}) {
+			t.Errorf("remaining = %v", got)
+		}
+	})
+
+	t.Run("Expire", func(t *testing.T) {
+		cp := NewChunkPipe[int]()
+		cp.Push([]int{1})
+		cp.Lease(ctx, 10*time.Millisecond)
+
+		waitCtx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+		id, val, err := cp.Lease(waitCtx, time.Minute)
+		if err != nil || val[0] != 1 || cp.Attempts(id) != 2 {
+			t.Errorf("Lease after expiry = %v, %v, attempts = %d", val, err, cp.Attempts(id))
+		}
+	})
+
+	t.Run("DeadLetter", func(t *testing.T) {
+		dlq := NewChunkPipe[int]()
+		cp := NewChunkPipe[int](WithDeadLetter(dlq, 2))
+		cp.Push([]int{42}).Close()
+
+		for i := 0; i < 2; i++ {
+			id, _, err := cp.Lease(ctx, time.Minute)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cp.Nack(id)
+		}
+		if _, _, err := cp.Lease(ctx, time.Minute); err != io.EOF {
+			t.Errorf("Lease on drained closed pipe = %v, want io.EOF", err)
+		}
+		if got := dlq.ValueSlice(); !reflect.DeepEqual(got, []int{42}) {
+			t.Errorf("dead letters = %v", got)
+		}
+	})
+
+	t.Run("AckLastOnClosed", func(t *testing.T) {
+		cp := NewChunkPipe[int]()
+		cp.Push([]int{1})
+		id, _, err := cp.Lease(ctx, time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cp.Close()
+
+		done := make(chan error, 1)
+		go func() {
+			_, _, err := cp.Lease(ctx, time.Minute)
+			done <- err
+		}()
+		time.Sleep(10 * time.Millisecond)
+		cp.Ack(id)
+		select {
+		case err := <-done:
+			if err != io.EOF {
+				t.Errorf("Lease after last Ack = %v, want io.EOF", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Lease not woken by the last Ack")
+		}
+	})
+}
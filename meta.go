@@ -0,0 +1,103 @@
+package chunkpipe
+
+import "time"
+
+// ChunkMeta 是塊的中繼資料
+type ChunkMeta struct {
+	// 單調遞增的塊 ID，從 1 開始，切分後的塊保留原本的 ID
+	ID uint64
+	// 塊被插入的時間
+	Enqueued time.Time
+	// 插入時附加的標籤，由所有持有者共用，不應修改
+	Tags map[string]string
+	// 塊的到期時間，零值表示不會過期
+	Expires time.Time
+}
+
+// newMeta 為新塊分配 ID 與插入時間，需持有寫鎖
+func (cl *ChunkPipe[T]) newMeta() ChunkMeta {
+	cl.lastID++
+	return ChunkMeta{
+		ID:       cl.lastID,
+		Enqueued: time.Now(),
+	}
+}
+
+// PushMeta 將數據作為一個塊插入並附加標籤，返回該塊的中繼資料。
+// 錯誤與 TryPush 相同；數據為空或被 BeforePush 轉換為空時不插入，返回零值。
+func (cl *ChunkPipe[T]) PushMeta(data []T, tags map[string]string) (ChunkMeta, error) {
+	return cl.push(data, tags, cl.ttl)
+}
+
+// PopChunkFrontMeta 從頭部彈出一個塊及其中繼資料
+func (cl *ChunkPipe[T]) PopChunkFrontMeta() ([]T, ChunkMeta, bool) {
+	if cl.hooks != nil && !cl.beforePop(EventPopChunkFront) {
+		return nil, ChunkMeta{}, false
+	}
+	cl.lock()
+	cl.reclaim()
+
+	list := cl.list
+	listLen := len(list)
+	if listLen > 0 {
+		cl.offset = list[0].off
+		ret := cl.detach(&list[0])
+		meta := list[0].meta
+		cl.release(&list[0])
+		cl.list = list[1:]
+		pe := cl.event(EventPopChunkFront, len(ret))
+		if cl.hooks != nil {
+			pe.after = cl.afterPop(EventPopChunkFront, [][]T{ret})
+		}
+		cl.mu.Unlock()
+		pe.publish()
+		// go cl.valueCache.dropValueCacheBefore(len(ret))
+		return ret, meta, true
+	}
+	cl.mu.Unlock()
+	return nil, ChunkMeta{}, false
+}
+
+// PopChunkEndMeta 從尾部彈出一個塊及其中繼資料
+func (cl *ChunkPipe[T]) PopChunkEndMeta() ([]T, ChunkMeta, bool) {
+	// 因為太麻煩所以直接清空
+	// go cl.valueCache.clearValueCache()
+	if cl.hooks != nil && !cl.beforePop(EventPopChunkEnd) {
+		return nil, ChunkMeta{}, false
+	}
+	var pe pendingEvent
+	defer pe.publish()
+	cl.lock()
+	defer cl.mu.Unlock()
+	cl.reclaim()
+
+	list := cl.list
+	listLen := len(list)
+	listLenMinusOne := listLen - 1
+
+	if listLen > 0 {
+		ret := cl.detach(&list[listLenMinusOne])
+		meta := list[listLenMinusOne].meta
+		cl.release(&list[listLenMinusOne])
+		cl.list = list[:listLenMinusOne]
+		cl.clampCursors()
+		pe = cl.event(EventPopChunkEnd, len(ret))
+		if cl.hooks != nil {
+			pe.after = cl.afterPop(EventPopChunkEnd, [][]T{ret})
+		}
+		return ret, meta, true
+	}
+	return nil, ChunkMeta{}, false
+}
+
+// Meta 返回目前塊的中繼資料，可以與其他操作並發呼叫
+func (it *ChunkIterator[T]) Meta() ChunkMeta {
+	it.pipe.rlock()
+	defer it.pipe.mu.RUnlock()
+	list := it.pipe.list
+
+	if it.pos < len(list) && it.pos >= 0 {
+		return list[it.pos].meta
+	}
+	return ChunkMeta{}
+}
@@ -0,0 +1,127 @@
+package chunkpipe
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestChunkMeta(t *testing.T) {
+	before := time.Now()
+	cp := NewChunkPipe[int]()
+	m1, err := cp.PushMeta([]int{1, 2, 3}, map[string]string{"trace": "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cp.Push([]int{4})
+	m3, _ := cp.PushMeta([]int{5, 6}, map[string]string{"trace": "c"})
+
+	if m1.ID != 1 || m3.ID != 3 {
+		t.Errorf("IDs = %d, %d, want 1, 3", m1.ID, m3.ID)
+	}
+	if m1.Enqueued.Before(before) || m3.Enqueued.Before(m1.Enqueued) {
+		t.Errorf("Enqueued = %v, %v", m1.Enqueued, m3.Enqueued)
+	}
+
+	iter := cp.ChunkIter()
+	var ids []uint64
+	for iter.Next() {
+		ids = append(ids, iter.Meta().ID)
+	}
+	if len(ids) != 3 || ids[0] != 1 || ids[1] != 2 || ids[2] != 3 {
+		t.Errorf("iterator IDs = %v", ids)
+	}
+
+	// 部分彈出後剩餘的塊保留中繼資料
+	cp.PopFront()
+	val, meta, ok := cp.PopChunkFrontMeta()
+	if !ok || len(val) != 2 || meta.ID != 1 || meta.Tags["trace"] != "a" {
+		t.Errorf("PopChunkFrontMeta = %v, %+v, %v", val, meta, ok)
+	}
+	val, meta, ok = cp.PopChunkEndMeta()
+	if !ok || len(val) != 2 || meta.ID != m3.ID || !meta.Enqueued.Equal(m3.Enqueued) || meta.Tags["trace"] != "c" {
+		t.Errorf("PopChunkEndMeta = %v, %+v, %v", val, meta, ok)
+	}
+
+	cp.Close()
+	if _, err := cp.PushMeta([]int{7}, nil); err != ErrClosed {
+		t.Errorf("PushMeta after Close = %v", err)
+	}
+	cp.PopChunkFront()
+	if _, meta, ok := cp.PopChunkFrontMeta(); ok || meta.ID != 0 {
+		t.Errorf("PopChunkFrontMeta on empty pipe = %+v, %v", meta, ok)
+	}
+}
+
+func TestChunkMetaSplit(t *testing.T) {
+	cp := NewChunkPipe[int]()
+	m, _ := cp.PushMeta([]int{1, 2, 3, 4}, map[string]string{"k": "v"})
+	cp.Rotate(2)
+	iter := cp.ChunkIter()
+	for iter.Next() {
+		if got := iter.Meta(); got.ID != m.ID || got.Tags["k"] != "v" {
+			t.Errorf("split chunk meta = %+v, want ID %d", got, m.ID)
+		}
+	}
+
+	id, _, err := cp.Lease(context.Background(), time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cp.Nack(id)
+	if _, meta, _ := cp.PopChunkFrontMeta(); meta.ID != m.ID {
+		t.Errorf("requeued chunk meta = %+v", meta)
+	}
+}
+
+func TestChunkMetaPutBack(t *testing.T) {
+	cp := NewChunkPipe[byte]()
+	m, _ := cp.PushMeta([]byte("héllo"), map[string]string{"k": "v"})
+	ReadRune(cp)
+	UnreadRune(cp)
+	if _, meta, _ := cp.PopChunkFrontMeta(); meta.ID != m.ID || meta.Tags["k"] != "v" {
+		t.Errorf("unread rune meta = %+v, want ID %d", meta, m.ID)
+	}
+	cp.Clear()
+
+	// ctx 結束時被放回的塊保留中繼資料
+	m, _ = cp.PushMeta([]byte("ab"), map[string]string{"k": "w"})
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := cp.ToChan(ctx)
+	<-ch
+	cancel()
+	for range ch {
+	}
+	cp.Close()
+	if val, meta, _ := cp.PopChunkFrontMeta(); string(val) != "b" || meta.ID != m.ID || meta.Tags["k"] != "w" {
+		t.Errorf("put back chunk = %q, %+v, want ID %d", val, meta, m.ID)
+	}
+
+	// 複製出的 ChunkPipe 延續 ID
+	src := NewChunkPipe[int]()
+	src.Push([]int{1}).Push([]int{2})
+	if m, _ := src.Clone().PushMeta([]int{3}, nil); m.ID != 3 {
+		t.Errorf("clone ID = %d, want 3", m.ID)
+	}
+}
+
+func TestChunkMetaConcurrent(t *testing.T) {
+	cp := NewChunkPipe[int]()
+	cp.Push([]int{0})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 1; i < 100; i++ {
+			cp.Push([]int{i})
+			cp.PopChunkFront()
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		iter := cp.ChunkIter()
+		for iter.Next() {
+			iter.V()
+			iter.Meta()
+		}
+	}
+	<-done
+}
@@ -1,14 +1,69 @@
 package chunkpipe
 
-// 插入數據到 ChunkPipe，支援泛型和鏈式呼叫
+import (
+	"slices"
+	"time"
+)
+
+// 插入數據到 ChunkPipe，支援泛型和鏈式呼叫，已關閉的 ChunkPipe 會忽略插入
 func (cl *ChunkPipe[T]) Push(data []T) *ChunkPipe[T] {
+	cl.push(data, nil, cl.ttl)
+	return cl
+}
+
+// push 插入數據並附加標籤，ttl 大於 0 時塊會在 ttl 之後過期。
+// 返回新塊的中繼資料；沒有插入數據時中繼資料為零值。
+func (cl *ChunkPipe[T]) push(data []T, tags map[string]string, ttl time.Duration) (ChunkMeta, error) {
+	if cl.hooks != nil {
+		var err error
+		if data, err = cl.beforePush(data); err != nil {
+			return ChunkMeta{}, err
+		}
+	}
+
 	dataLen := len(data)
 
 	if dataLen == 0 {
-		return cl
+		return ChunkMeta{}, nil
+	}
+
+	// 壓縮在鎖外進行
+	var packed []byte
+	if cl.comp != nil {
+		packed = cl.packChunk(data)
+	}
+
+	var pe pendingEvent
+	cl.lock()
+	if cl.closed {
+		cl.mu.Unlock()
+		return ChunkMeta{}, ErrClosed
+	}
+	cl.appendChunk(data, packed)
+	last := &cl.list[len(cl.list)-1]
+	last.meta.Tags = tags
+	if ttl > 0 {
+		last.meta.Expires = last.meta.Enqueued.Add(ttl)
+		cl.scheduleExpiry(last.meta.Expires)
+	}
+	meta := last.meta
+	pe = cl.event(EventPush, dataLen)
+	cl.mu.Unlock()
+	pe.publish()
+	// go func() {
+	// 	for i := range data {
+	// 		cl.valueCache.setValueCache(off+i, &data[i])
+	// 	}
+	// }()
+
+	if cl.hooks != nil {
+		cl.afterPush(data)
 	}
+	return meta, nil
+}
 
-	cl.mu.Lock()
+// appendChunk 將非空的塊附加到尾部，需持有寫鎖
+func (cl *ChunkPipe[T]) appendChunk(data []T, packed []byte) {
 	off := cl.offset
 	list := cl.list
 	listLen := len(list)
@@ -17,23 +72,110 @@ func (cl *ChunkPipe[T]) Push(data []T) *ChunkPipe[T] {
 		off = list[listLen-1].off
 	}
 
+	dataLen := len(data)
+	var seg *segment
+	rawLen := 0
+	if packed != nil {
+		rawLen = dataLen
+		data = nil
+	} else if cl.segs != nil {
+		data, seg = cl.storeChunk(data)
+	}
+
 	cl.list = append(cl.list, chunk[T]{
-		val: data,
-		off: off + dataLen,
+		val:    data,
+		off:    off + dataLen,
+		seg:    seg,
+		packed: packed,
+		rawLen: rawLen,
+		meta:   cl.newMeta(),
 	})
-	cl.mu.Unlock()
-	// go func() {
-	// 	for i := range data {
-	// 		cl.valueCache.setValueCache(off+i, &data[i])
-	// 	}
-	// }()
+	cl.signal()
+}
 
-	return cl
+// clearChunks 移除所有塊，需持有寫鎖
+func (cl *ChunkPipe[T]) clearChunks() {
+	cl.reclaim()
+	list := cl.list
+	for i := range list {
+		cl.release(&list[i])
+	}
+	if len(list) != 0 {
+		cl.offset = list[len(list)-1].off
+	}
+	clear(list)
+	cl.list = list[:0]
+}
+
+// locate 返回包含相對索引 index 的塊，需持有鎖，index 必須在範圍內
+func (cl *ChunkPipe[T]) locate(index int) int {
+	target := index + cl.offset
+	list := cl.list
+	l, r := 0, len(list)-1
+	for l < r {
+		m := int(uint(l+r) >> 1)
+		if list[m].off > target {
+			r = m
+		} else {
+			l = m + 1
+		}
+	}
+	return l
+}
+
+// splitAt 確保相對索引 index 位於某個塊的開頭，並返回該塊的位置，需持有寫鎖。
+// index 等於長度時返回塊數。
+func (cl *ChunkPipe[T]) splitAt(index int) int {
+	if index >= cl.size() {
+		return len(cl.list)
+	}
+	i := cl.locate(index)
+	start := cl.chunkStart(i) - cl.offset
+	if start == index {
+		return i
+	}
+
+	cl.unpack(&cl.list[i])
+	c := cl.list[i]
+	n := index - start
+	head := chunk[T]{
+		off:    cl.offset + index,
+		val:    c.val[:n:n],
+		seg:    c.seg,
+		shared: c.shared,
+		meta:   c.meta,
+	}
+	if c.seg != nil {
+		c.seg.live++
+	}
+	c.val = c.val[n:]
+	cl.list = slices.Insert(cl.list, i, head)
+	cl.list[i+1] = c
+	return i + 1
+}
+
+// chunkLens 返回每個塊的長度，需持有鎖
+func (cl *ChunkPipe[T]) chunkLens() []int {
+	lens := make([]int, len(cl.list))
+	for i := range cl.list {
+		lens[i] = cl.list[i].off - cl.chunkStart(i)
+	}
+	return lens
+}
+
+// reindex 在塊被重新排列後依 lens 重新計算每個塊的 off，需持有寫鎖
+func (cl *ChunkPipe[T]) reindex(lens []int) {
+	off := cl.offset
+	for i := range cl.list {
+		off += lens[i]
+		cl.list[i].off = off
+	}
 }
 
 func (cl *ChunkPipe[T]) Get(index int) (T, bool) {
 	var zero T
-	cl.mu.RLock()
+	cl.rlock()
+	defer cl.mu.RUnlock()
 
 	list := cl.list
 	listLen := len(list)
@@ -55,8 +197,8 @@ func (cl *ChunkPipe[T]) Get(index int) (T, bool) {
 	}
 
 	if list[l].off > target {
-		off := list[0]
-		val := off.val
+		off := &list[0]
+		val := cl.load(off)
 		target = len(val) - (off.off - target)
 		return val[target], true
 	}
@@ -70,69 +212,56 @@ func (cl *ChunkPipe[T]) Get(index int) (T, bool) {
 		}
 	}
 
-	chunk := list[r]
-	val := chunk.val
+	chunk := &list[r]
+	val := cl.load(chunk)
 	target = len(val) - (chunk.off - target)
 	result := val[target]
-	cl.mu.RUnlock()
 	// go cl.valueCache.setValueCache(index, &result)
 	return result, true
 }
 
 // 從頭部彈出數據
 func (cl *ChunkPipe[T]) PopChunkFront() ([]T, bool) {
-	cl.mu.Lock()
-
-	list := cl.list
-	listLen := len(list)
-	if listLen > 0 {
-		cl.offset = list[0].off
-		ret := list[0].val
-		cl.list = list[1:]
-		cl.mu.Unlock()
-		// go cl.valueCache.dropValueCacheBefore(len(ret))
-		return ret, true
-	}
-	cl.mu.Unlock()
-	return nil, false
+	ret, _, ok := cl.PopChunkFrontMeta()
+	return ret, ok
 }
 
 // 從尾部彈出數據
 func (cl *ChunkPipe[T]) PopChunkEnd() ([]T, bool) {
-	// 因為太麻煩所以直接清空
-	// go cl.valueCache.clearValueCache()
-	cl.mu.Lock()
-	defer cl.mu.Unlock()
-
-	list := cl.list
-	listLen := len(list)
-	listLenMinusOne := listLen - 1
-
-	if listLen > 0 {
-		ret := list[listLenMinusOne].val
-		cl.list = list[:listLenMinusOne]
-		return ret, true
-	}
-	return nil, false
+	ret, _, ok := cl.PopChunkEndMeta()
+	return ret, ok
 }
 
 func (cl *ChunkPipe[T]) PopFront() (T, bool) {
 	// go cl.valueCache.dropFirstValueCache()
-	cl.mu.Lock()
+	if cl.hooks != nil && !cl.beforePop(EventPopFront) {
+		var zero T
+		return zero, false
+	}
+	var pe pendingEvent
+	defer pe.publish()
+	cl.lock()
 	defer cl.mu.Unlock()
+	cl.reclaim()
 
 	list := cl.list
 	listLen := len(list)
 
 	if listLen > 0 {
+		cl.unpack(&list[0])
 		val := list[0].val
 		ret := val[0]
 		val = val[1:]
 		list[0].val = val
 		cl.offset++
 		if len(val) == 0 {
+			cl.release(&list[0])
 			cl.list = list[1:]
 		}
+		pe = cl.event(EventPopFront, 1)
+		if cl.hooks != nil {
+			pe.after = cl.afterPop(EventPopFront, [][]T{{ret}})
+		}
 		return ret, true
 	}
 	var ret T
@@ -142,8 +271,15 @@ func (cl *ChunkPipe[T]) PopFront() (T, bool) {
 // 從尾部彈出數據
 func (cl *ChunkPipe[T]) PopEnd() (T, bool) {
 	// go cl.valueCache.clearValueCache()
-	cl.mu.Lock()
+	if cl.hooks != nil && !cl.beforePop(EventPopEnd) {
+		var zero T
+		return zero, false
+	}
+	var pe pendingEvent
+	defer pe.publish()
+	cl.lock()
 	defer cl.mu.Unlock()
+	cl.reclaim()
 
 	list := cl.list
 	listLen := len(list)
@@ -154,11 +290,13 @@ func (cl *ChunkPipe[T]) PopEnd() (T, bool) {
 	}
 
 	listLenMinusOne := listLen - 1
+	cl.unpack(&list[listLenMinusOne])
 	val := list[listLenMinusOne].val
 	valLen := len(val)
 
 	if valLen == 0 {
 		// 如果當前塊為空，移除整塊
+		cl.release(&list[listLenMinusOne])
 		cl.list = list[:listLenMinusOne]
 		return ret, false
 	}
@@ -171,15 +309,201 @@ func (cl *ChunkPipe[T]) PopEnd() (T, bool) {
 
 	if valLen == 1 {
 		// 如果這是塊中的最後一個元素，移除整個塊
+		cl.release(&list[listLenMinusOne])
 		cl.list = list[:listLenMinusOne]
 	}
 
+	cl.clampCursors()
+	pe = cl.event(EventPopEnd, 1)
+	if cl.hooks != nil {
+		pe.after = cl.afterPop(EventPopEnd, [][]T{{ret}})
+	}
 	return ret, true
 }
 
+// PopFrontN 在一次加鎖中從頭部彈出 n 個元素，以塊視圖返回，不足 n 個時彈出全部。
+// 邊界所在的塊會被切分，只彈出需要的部分。
+func (cl *ChunkPipe[T]) PopFrontN(n int) [][]T {
+	if cl.hooks != nil && !cl.beforePop(EventPopFront) {
+		return nil
+	}
+	var pe pendingEvent
+	defer pe.publish()
+	cl.lock()
+	defer cl.mu.Unlock()
+	cl.reclaim()
+
+	ret, removed := cl.removeFront(n, true)
+	pe = cl.event(EventPopFront, removed)
+	if cl.hooks != nil && removed > 0 {
+		pe.after = cl.afterPop(EventPopFront, ret)
+	}
+	return ret
+}
+
+// PopEndN 在一次加鎖中從尾部彈出 n 個元素，以塊視圖按原本的順序返回，不足 n 個時彈出全部
+func (cl *ChunkPipe[T]) PopEndN(n int) [][]T {
+	if cl.hooks != nil && !cl.beforePop(EventPopEnd) {
+		return nil
+	}
+	var pe pendingEvent
+	defer pe.publish()
+	cl.lock()
+	defer cl.mu.Unlock()
+	cl.reclaim()
+
+	ret, removed := cl.removeEnd(n, true)
+	pe = cl.event(EventPopEnd, removed)
+	slices.Reverse(ret)
+	if cl.hooks != nil && removed > 0 {
+		pe.after = cl.afterPop(EventPopEnd, ret)
+	}
+	return ret
+}
+
+// Discard 從頭部丟棄最多 n 個元素並返回丟棄的數量，不會複製或返回數據
+func (cl *ChunkPipe[T]) Discard(n int) int {
+	var pe pendingEvent
+	defer pe.publish()
+	cl.lock()
+	defer cl.mu.Unlock()
+	cl.reclaim()
+
+	_, removed := cl.removeFront(n, false)
+	pe = cl.event(EventPopFront, removed)
+	return removed
+}
+
+// Truncate 只保留開頭的 n 個元素並返回移除的數量
+func (cl *ChunkPipe[T]) Truncate(n int) int {
+	var pe pendingEvent
+	defer pe.publish()
+	cl.lock()
+	defer cl.mu.Unlock()
+	cl.reclaim()
+
+	if n < 0 {
+		n = 0
+	}
+	_, removed := cl.removeEnd(cl.size()-n, false)
+	pe = cl.event(EventPopEnd, removed)
+	return removed
+}
+
+// removeFront 從頭部移除最多 n 個元素，collect 為 true 時以塊視圖返回被移除的部分，需持有寫鎖
+func (cl *ChunkPipe[T]) removeFront(n int, collect bool) ([][]T, int) {
+	var ret [][]T
+	removed := 0
+	list := cl.list
+	for n > 0 && len(list) > 0 {
+		c := &list[0]
+		size := c.off - cl.offset
+		if size <= n {
+			if collect {
+				ret = append(ret, cl.detach(c))
+			}
+			cl.offset = c.off
+			cl.release(c)
+			list = list[1:]
+			n -= size
+			removed += size
+			continue
+		}
+		cl.unpack(c)
+		if collect {
+			part := c.val[:n:n]
+			if c.seg != nil {
+				part = slices.Clone(part)
+			}
+			ret = append(ret, part)
+		}
+		c.val = c.val[n:]
+		cl.offset += n
+		removed += n
+		n = 0
+	}
+	cl.list = list
+	return ret, removed
+}
+
+// removeEnd 從尾部移除最多 n 個元素，collect 為 true 時以倒序的塊視圖返回被移除的部分，需持有寫鎖
+func (cl *ChunkPipe[T]) removeEnd(n int, collect bool) ([][]T, int) {
+	var ret [][]T
+	removed := 0
+	list := cl.list
+	for n > 0 && len(list) > 0 {
+		last := len(list) - 1
+		c := &list[last]
+		size := c.off - cl.chunkStart(last)
+		if size <= n {
+			if collect {
+				ret = append(ret, cl.detach(c))
+			}
+			cl.release(c)
+			list = list[:last]
+			n -= size
+			removed += size
+			continue
+		}
+		cl.unpack(c)
+		keep := len(c.val) - n
+		if collect {
+			part := c.val[keep:]
+			if c.seg != nil {
+				part = slices.Clone(part)
+			}
+			ret = append(ret, part)
+		}
+		c.val = c.val[:keep:keep]
+		c.off -= n
+		removed += n
+		n = 0
+	}
+	cl.list = list
+	cl.clampCursors()
+	return ret, removed
+}
+
+// PopFrontInto 從頭部彈出最多 len(dst) 個元素並複製到 dst，返回複製的元素數
+func (cl *ChunkPipe[T]) PopFrontInto(dst []T) int {
+	if cl.hooks != nil && !cl.beforePop(EventPopFront) {
+		return 0
+	}
+	var pe pendingEvent
+	defer pe.publish()
+	cl.lock()
+	defer cl.mu.Unlock()
+	cl.reclaim()
+
+	k := 0
+	list := cl.list
+	for k < len(dst) && len(list) > 0 {
+		c := &list[0]
+		if c.off-cl.offset > len(dst)-k {
+			cl.unpack(c)
+		}
+		n := copy(dst[k:], cl.load(c))
+		k += n
+		if cl.offset+n == c.off {
+			cl.offset = c.off
+			cl.release(c)
+			list = list[1:]
+			continue
+		}
+		c.val = c.val[n:]
+		cl.offset += n
+	}
+	cl.list = list
+	pe = cl.event(EventPopFront, k)
+	if cl.hooks != nil && k > 0 {
+		pe.after = cl.afterPop(EventPopFront, [][]T{dst[:k:k]})
+	}
+	return k
+}
+
 // ValueSlice 返回所有值的切片
 func (cl *ChunkPipe[T]) ValueSlice() []T {
-	cl.mu.RLock()
+	cl.rlock()
 	defer cl.mu.RUnlock()
 
 	list := cl.list
@@ -203,7 +527,7 @@ func (cl *ChunkPipe[T]) ValueSlice() []T {
 
 	k := 0
 	for i := range list {
-		for _, v := range list[i].val {
+		for _, v := range cl.load(&list[i]) {
 			ret[k] = v
 			k++
 		}
@@ -213,7 +537,7 @@ func (cl *ChunkPipe[T]) ValueSlice() []T {
 
 // ChunkSlice 返回所有數據塊的切片
 func (cl *ChunkPipe[T]) ChunkSlice() [][]T {
-	cl.mu.RLock()
+	cl.rlock()
 	defer cl.mu.RUnlock()
 
 	list := cl.list
@@ -235,7 +559,7 @@ func (cl *ChunkPipe[T]) ChunkSlice() [][]T {
 	}
 
 	for i := range ret {
-		ret[i] = list[i].val
+		ret[i] = cl.detach(&list[i])
 	}
 	return ret
 }
@@ -281,16 +605,20 @@ func (it *ValueIterator[T]) V() T {
 // ChunkIterator 的方法
 func (it *ChunkIterator[T]) Next() bool {
 	it.pos++
+	it.pipe.rlock()
+	defer it.pipe.mu.RUnlock()
 	list := it.pipe.list
 
 	return it.pos < len(list)
 }
 
 func (it *ChunkIterator[T]) V() []T {
+	it.pipe.rlock()
+	defer it.pipe.mu.RUnlock()
 	list := it.pipe.list
 
 	if it.pos < len(list) && it.pos >= 0 {
-		return list[it.pos].val
+		return it.pipe.detach(&list[it.pos])
 	}
 	var zero []T
 	return zero
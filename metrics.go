@@ -0,0 +1,297 @@
+package chunkpipe
+
+import (
+	"expvar"
+	"fmt"
+	"io"
+	"math/bits"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// 事件類型的數量，用於按類型計數
+const eventKinds = 7
+
+var (
+	// 等待鎖的時間分桶上限，單位為奈秒
+	lockWaitBounds = []int64{1e3, 1e4, 1e5, 1e6, 1e7, 1e8, 1e9}
+	// 插入塊的長度分桶上限
+	chunkSizeBounds = []int64{1, 4, 16, 64, 256, 1024, 4096, 16384, 65536}
+)
+
+// metrics 保存 ChunkPipe 的累計指標。
+// 計數只在持有寫鎖時更新，由 ChunkPipe.mu 保護；直方圖使用原子操作，讀鎖的等待時間也可以記錄。
+type metrics struct {
+	pushes      uint64
+	pops        [eventKinds]uint64
+	elementsIn  uint64
+	elementsOut uint64
+	requeues    uint64
+	requeued    uint64
+	chunkSize   histogram
+	lockWait    histogram
+	rlockWait   histogram
+}
+
+// histogram 是固定分桶的直方圖
+type histogram struct {
+	bounds []int64
+	// 最後一個桶沒有上限
+	counts []atomic.Uint64
+	count  atomic.Uint64
+	sum    atomic.Int64
+	// 輸出時乘上的單位
+	scale float64
+}
+
+// Histogram 是直方圖的快照
+type Histogram struct {
+	// 各桶的上限，不包含最後一個沒有上限的桶
+	Bounds []float64
+	// 各桶的計數，比 Bounds 多一個
+	Counts []uint64
+	Count  uint64
+	Sum    float64
+}
+
+// PopCounts 是按類型統計的取出次數
+type PopCounts struct {
+	Front      uint64
+	ChunkFront uint64
+	End        uint64
+	ChunkEnd   uint64
+	Clear      uint64
+	Expire     uint64
+}
+
+func newMetrics() *metrics {
+	m := &metrics{}
+	m.chunkSize.init(chunkSizeBounds, 1)
+	m.lockWait.init(lockWaitBounds, 1e-9)
+	m.rlockWait.init(lockWaitBounds, 1e-9)
+	return m
+}
+
+// WithoutMetrics 停用內建指標，Stats 中的計數與直方圖會保持為零值
+func WithoutMetrics[T any]() Option[T] {
+	return func(cp *ChunkPipe[T]) {
+		cp.metrics = nil
+	}
+}
+
+func (h *histogram) init(bounds []int64, scale float64) {
+	h.bounds = bounds
+	h.counts = make([]atomic.Uint64, len(bounds)+1)
+	h.scale = scale
+}
+
+func (h *histogram) observe(v int64) {
+	i := 0
+	for i < len(h.bounds) && v > h.bounds[i] {
+		i++
+	}
+	h.counts[i].Add(1)
+	h.count.Add(1)
+	h.sum.Add(v)
+}
+
+func (h *histogram) snapshot() Histogram {
+	if h.counts == nil {
+		return Histogram{}
+	}
+	s := Histogram{
+		Bounds: make([]float64, len(h.bounds)),
+		Counts: make([]uint64, len(h.counts)),
+		Count:  h.count.Load(),
+		Sum:    float64(h.sum.Load()) * h.scale,
+	}
+	for i, b := range h.bounds {
+		s.Bounds[i] = float64(b) * h.scale
+	}
+	for i := range h.counts {
+		s.Counts[i] = h.counts[i].Load()
+	}
+	return s
+}
+
+// lock 取得寫鎖，啟用指標時記錄需要等待的取得的等待時間，未競爭的取得不記錄
+func (cl *ChunkPipe[T]) lock() {
+	m := cl.metrics
+	if m == nil || cl.mu.TryLock() {
+		if m == nil {
+			cl.mu.Lock()
+		}
+		return
+	}
+	start := time.Now()
+	cl.mu.Lock()
+	m.lockWait.observe(int64(time.Since(start)))
+}
+
+// rlock 取得讀鎖。已關閉的 ChunkPipe 中有已過期的塊時，先改取寫鎖移除它們再重新取得讀鎖。
+func (cl *ChunkPipe[T]) rlock() {
+	for {
+		cl.acquireRLock()
+		if !cl.closed || !cl.hasExpired() {
+			return
+		}
+		cl.mu.RUnlock()
+		cl.lock()
+		cl.expireClosed()
+		cl.mu.Unlock()
+	}
+}
+
+// acquireRLock 取得讀鎖，啟用指標時記錄需要等待的取得的等待時間，未競爭的取得不記錄
+func (cl *ChunkPipe[T]) acquireRLock() {
+	m := cl.metrics
+	if m == nil || cl.mu.TryRLock() {
+		if m == nil {
+			cl.mu.RLock()
+		}
+		return
+	}
+	start := time.Now()
+	cl.mu.RLock()
+	m.rlockWait.observe(int64(time.Since(start)))
+}
+
+// record 記錄一次插入或取出，需持有寫鎖
+func (m *metrics) record(kind EventKind, count int) {
+	if kind == EventPush {
+		m.pushes++
+		m.elementsIn += uint64(count)
+		m.chunkSize.observe(int64(count))
+		return
+	}
+	m.pops[bits.TrailingZeros(uint(kind))]++
+	m.elementsOut += uint64(count)
+}
+
+// recordRequeue 記錄一次將數據放回頭部，需持有寫鎖
+func (m *metrics) recordRequeue(count int) {
+	m.requeues++
+	m.requeued += uint64(count)
+}
+
+// fill 將指標寫入 Stats，需持有讀鎖
+func (m *metrics) fill(st *Stats) {
+	st.Pushes = m.pushes
+	st.Pops = PopCounts{
+		Front:      m.pops[bits.TrailingZeros(uint(EventPopFront))],
+		ChunkFront: m.pops[bits.TrailingZeros(uint(EventPopChunkFront))],
+		End:        m.pops[bits.TrailingZeros(uint(EventPopEnd))],
+		ChunkEnd:   m.pops[bits.TrailingZeros(uint(EventPopChunkEnd))],
+		Clear:      m.pops[bits.TrailingZeros(uint(EventClear))],
+		Expire:     m.pops[bits.TrailingZeros(uint(EventExpire))],
+	}
+	st.ElementsIn = m.elementsIn
+	st.ElementsOut = m.elementsOut
+	st.Requeues = m.requeues
+	st.ElementsRequeued = m.requeued
+	st.ChunkSize = m.chunkSize.snapshot()
+	st.LockWait = m.lockWait.snapshot()
+	st.RLockWait = m.rlockWait.snapshot()
+}
+
+// PublishExpvar 以 name 將 Stats 註冊到 expvar，name 重複時會 panic
+func (cl *ChunkPipe[T]) PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() any {
+		return cl.Stats()
+	}))
+}
+
+// WritePrometheus 以 Prometheus 文字格式寫出指標，metric 名稱以 namespace 為前綴
+func (cl *ChunkPipe[T]) WritePrometheus(w io.Writer, namespace string) error {
+	st := cl.Stats()
+	pw := &promWriter{w: w, ns: namespace}
+
+	pw.header("pushes_total", "counter", "Number of Push calls that added data.")
+	pw.value("pushes_total", "", float64(st.Pushes))
+
+	pw.header("pops_total", "counter", "Number of pop operations by kind.")
+	pw.value("pops_total", `kind="front"`, float64(st.Pops.Front))
+	pw.value("pops_total", `kind="chunk_front"`, float64(st.Pops.ChunkFront))
+	pw.value("pops_total", `kind="end"`, float64(st.Pops.End))
+	pw.value("pops_total", `kind="chunk_end"`, float64(st.Pops.ChunkEnd))
+	pw.value("pops_total", `kind="clear"`, float64(st.Pops.Clear))
+	pw.value("pops_total", `kind="expire"`, float64(st.Pops.Expire))
+
+	pw.header("elements_in_total", "counter", "Number of elements pushed.")
+	pw.value("elements_in_total", "", float64(st.ElementsIn))
+	pw.header("elements_out_total", "counter", "Number of elements removed.")
+	pw.value("elements_out_total", "", float64(st.ElementsOut))
+	pw.header("requeues_total", "counter", "Number of times data was put back at the head.")
+	pw.value("requeues_total", "", float64(st.Requeues))
+	pw.header("elements_requeued_total", "counter", "Number of elements put back at the head.")
+	pw.value("elements_requeued_total", "", float64(st.ElementsRequeued))
+
+	pw.header("length", "gauge", "Current number of elements.")
+	pw.value("length", "", float64(st.Len))
+	pw.header("chunks", "gauge", "Current number of chunks.")
+	pw.value("chunks", "", float64(st.Chunks))
+
+	pw.header("chunk_size", "histogram", "Length of pushed chunks.")
+	pw.histogram("chunk_size", "", st.ChunkSize)
+
+	pw.header("lock_wait_seconds", "histogram", "Time spent waiting for the pipe lock.")
+	pw.histogram("lock_wait_seconds", `mode="write"`, st.LockWait)
+	pw.histogram("lock_wait_seconds", `mode="read"`, st.RLockWait)
+	return pw.err
+}
+
+// MetricsHandler 返回以 Prometheus 文字格式輸出指標的 http.Handler
+func (cl *ChunkPipe[T]) MetricsHandler(namespace string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		cl.WritePrometheus(w, namespace)
+	})
+}
+
+// promWriter 寫出 Prometheus 文字格式，保留第一個錯誤
+type promWriter struct {
+	w   io.Writer
+	ns  string
+	err error
+}
+
+func (pw *promWriter) printf(format string, args ...any) {
+	if pw.err != nil {
+		return
+	}
+	_, pw.err = fmt.Fprintf(pw.w, format, args...)
+}
+
+func (pw *promWriter) header(name, typ, help string) {
+	pw.printf("# HELP %s_%s %s\n# TYPE %s_%s %s\n", pw.ns, name, help, pw.ns, name, typ)
+}
+
+func (pw *promWriter) value(name, labels string, v float64) {
+	if labels != "" {
+		labels = "{" + labels + "}"
+	}
+	pw.printf("%s_%s%s %s\n", pw.ns, name, labels, strconv.FormatFloat(v, 'g', -1, 64))
+}
+
+func (pw *promWriter) histogram(name, labels string, h Histogram) {
+	sep := ""
+	if labels != "" {
+		sep = ","
+	}
+	var cum uint64
+	for i, n := range h.Counts {
+		cum += n
+		le := "+Inf"
+		if i < len(h.Bounds) {
+			le = strconv.FormatFloat(h.Bounds[i], 'g', -1, 64)
+		}
+		pw.value(name+"_bucket", labels+sep+`le="`+le+`"`, float64(cum))
+	}
+	if len(h.Counts) == 0 {
+		pw.value(name+"_bucket", labels+sep+`le="+Inf"`, 0)
+	}
+	pw.value(name+"_sum", labels, h.Sum)
+	pw.value(name+"_count", labels, float64(h.Count))
+}
@@ -0,0 +1,146 @@
+package chunkpipe
+
+import (
+	"bytes"
+	"expvar"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetrics(t *testing.T) {
+	t.Run("Counters", func(t *testing.T) {
+		cp := NewChunkPipe[int]()
+		cp.Push([]int{1, 2, 3}).Push([]int{4, 5}).Push(nil)
+		cp.PopFront()
+		cp.PopChunkEnd()
+		cp.PopFrontN(1)
+		cp.Clear()
+		cp.PopFront()
+
+		st := cp.Stats()
+		if st.Pushes != 2 || st.ElementsIn != 5 || st.ElementsOut != 5 {
+			t.Errorf("Pushes, ElementsIn, ElementsOut = %d, %d, %d", st.Pushes, st.ElementsIn, st.ElementsOut)
+		}
+		want := PopCounts{Front: 2, ChunkEnd: 1, Clear: 1}
+		if st.Pops != want {
+			t.Errorf("Pops = %+v, want %+v", st.Pops, want)
+		}
+		if st.ChunkSize.Count != 2 || st.ChunkSize.Sum != 5 {
+			t.Errorf("ChunkSize = %+v", st.ChunkSize)
+		}
+		// 長度 3 與 2 都落在 (1, 4] 的桶
+		if st.ChunkSize.Counts[1] != 2 {
+			t.Errorf("ChunkSize.Counts = %v", st.ChunkSize.Counts)
+		}
+		// 未競爭的取得不記錄等待時間
+		if st.LockWait.Count != 0 || st.RLockWait.Count != 0 {
+			t.Errorf("uncontended lock waits recorded: %d, %d", st.LockWait.Count, st.RLockWait.Count)
+		}
+	})
+
+	t.Run("Requeue", func(t *testing.T) {
+		cp := NewChunkPipe[byte]()
+		ch, cancel := cp.Subscribe(EventPush)
+		defer cancel()
+		cp.Push([]byte("ab"))
+		ReadRune(cp)
+		UnreadRune(cp)
+
+		st := cp.Stats()
+		if st.Pushes != 1 || st.ElementsIn != 2 || st.Requeues != 1 || st.ElementsRequeued != 1 {
+			t.Errorf("Pushes, ElementsIn, Requeues, ElementsRequeued = %d, %d, %d, %d",
+				st.Pushes, st.ElementsIn, st.Requeues, st.ElementsRequeued)
+		}
+		// 放回仍然發布插入事件
+		<-ch
+		if ev := <-ch; ev.Kind != EventPush || ev.Count != 1 || ev.Len != 2 {
+			t.Errorf("requeue event = %+v", ev)
+		}
+	})
+
+	t.Run("LockWait", func(t *testing.T) {
+		cp := NewChunkPipe[int]()
+		cp.Push([]int{1})
+		// 持有寫鎖讓 Push 與 Get 都需要等待
+		wait := func(fn func()) {
+			cp.mu.Lock()
+			done := make(chan struct{})
+			go func() {
+				fn()
+				close(done)
+			}()
+			time.Sleep(10 * time.Millisecond)
+			cp.mu.Unlock()
+			<-done
+		}
+		wait(func() { cp.Push([]int{2}) })
+		wait(func() { cp.Get(0) })
+
+		st := cp.Stats()
+		if st.LockWait.Count != 1 || st.RLockWait.Count != 1 {
+			t.Errorf("lock waits = %d, %d, want 1, 1", st.LockWait.Count, st.RLockWait.Count)
+		}
+		if st.LockWait.Sum <= 0 {
+			t.Errorf("LockWait.Sum = %v", st.LockWait.Sum)
+		}
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		cp := NewChunkPipe[int](WithoutMetrics[int]())
+		cp.Push([]int{1, 2})
+		cp.PopFront()
+		st := cp.Stats()
+		if st.Len != 1 || st.Pushes != 0 || st.LockWait.Count != 0 || st.ChunkSize.Counts != nil {
+			t.Errorf("Stats = %+v", st)
+		}
+	})
+
+	t.Run("Prometheus", func(t *testing.T) {
+		cp := NewChunkPipe[int]()
+		cp.Push([]int{1, 2, 3})
+		cp.PopEnd()
+
+		rec := httptest.NewRecorder()
+		cp.MetricsHandler("queue").ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+		body := rec.Body.String()
+		for _, line := range []string{
+			"# TYPE queue_pushes_total counter",
+			"queue_pushes_total 1",
+			`queue_pops_total{kind="end"} 1`,
+			"queue_elements_out_total 1",
+			"queue_length 2",
+			"queue_chunks 1",
+			`queue_chunk_size_bucket{le="4"} 1`,
+			`queue_chunk_size_bucket{le="+Inf"} 1`,
+			"queue_chunk_size_count 1",
+			`queue_lock_wait_seconds_bucket{mode="write",le="+Inf"}`,
+		} {
+			if !strings.Contains(body, line) {
+				t.Errorf("missing %q in:\n%s", line, body)
+			}
+		}
+
+		var disabled bytes.Buffer
+		if err := NewChunkPipe[int](WithoutMetrics[int]()).WritePrometheus(&disabled, "q"); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(disabled.String(), `q_chunk_size_bucket{le="+Inf"} 0`) {
+			t.Errorf("disabled output:\n%s", disabled.String())
+		}
+	})
+
+	t.Run("Expvar", func(t *testing.T) {
+		cp := NewChunkPipe[int]()
+		cp.Push([]int{1})
+		// 同名變數只能註冊一次，-count 大於 1 時沿用第一次註冊的
+		if expvar.Get("chunkpipe_test_metrics") == nil {
+			cp.PublishExpvar("chunkpipe_test_metrics")
+		}
+		v := expvar.Get("chunkpipe_test_metrics")
+		if v == nil || !strings.Contains(v.String(), `"Pushes":1`) {
+			t.Errorf("expvar = %v", v)
+		}
+	})
+}
@@ -0,0 +1,181 @@
+package chunkpipe
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+)
+
+// 預設的 mmap 段大小
+const defaultSegmentSize = 64 << 20
+
+// segment 是一個映射到記憶體的段檔案
+type segment struct {
+	path string
+	data []byte
+	used int
+	// 尚未被彈出的塊數
+	live int
+}
+
+// segmentStore 管理 ChunkPipe[byte] 的 mmap 段，由 ChunkPipe.mu 保護
+type segmentStore struct {
+	dir  string
+	size int
+	seq  int
+	cur  *segment
+	// 仍有塊在使用的段
+	active []*segment
+	// 所有塊都已彈出、等待解除映射的段
+	retired []*segment
+}
+
+// WithMmapSegments 讓 ChunkPipe[byte] 將塊存放在 dir 下的 mmap 段檔案中，而不是 Go 堆上。
+// 彈出、查看或迭代時，塊會被複製到堆上再交給呼叫者，因此段可以在任何時候安全地解除映射。
+// 段內所有塊都被彈出後，該段會被解除映射並刪除；Close 會將剩餘的塊搬到堆上並釋放所有段，
+// 未關閉就被回收的 ChunkPipe 則由 finalizer 釋放。
+// 若平台不支援 mmap 或建立段失敗，Push 會退回使用堆記憶體。
+func WithMmapSegments(dir string, segmentSize int) Option[byte] {
+	if segmentSize <= 0 {
+		segmentSize = defaultSegmentSize
+	}
+	return func(cp *ChunkPipe[byte]) {
+		s := &segmentStore{
+			dir:  dir,
+			size: segmentSize,
+		}
+		runtime.SetFinalizer(s, (*segmentStore).close)
+		cp.segs = s
+	}
+}
+
+// alloc 在段中分配 n 個位元組，必要時建立新段
+func (s *segmentStore) alloc(n int) (*segment, []byte, error) {
+	cur := s.cur
+	if cur == nil || len(cur.data)-cur.used < n {
+		size := s.size
+		if n > size {
+			// 超過段大小的塊獨佔一個段
+			size = n
+		}
+		seg, err := s.newSegment(size)
+		if err != nil {
+			return nil, nil, err
+		}
+		s.cur = seg
+		s.active = append(s.active, seg)
+		cur = seg
+	}
+
+	buf := cur.data[cur.used : cur.used+n : cur.used+n]
+	cur.used += n
+	cur.live++
+	return cur, buf, nil
+}
+
+func (s *segmentStore) newSegment(size int) (*segment, error) {
+	s.seq++
+	path := filepath.Join(s.dir, fmt.Sprintf("chunkpipe-%d-%p-%d.seg", os.Getpid(), s, s.seq))
+	data, err := mapSegment(path, size)
+	if err != nil {
+		return nil, err
+	}
+	return &segment{path: path, data: data}, nil
+}
+
+// release 在段中的一個塊被移除時呼叫
+func (s *segmentStore) release(seg *segment) {
+	seg.live--
+	if seg.live == 0 {
+		if seg == s.cur {
+			s.cur = nil
+		}
+		if i := slices.Index(s.active, seg); i >= 0 {
+			s.active = slices.Delete(s.active, i, i+1)
+		}
+		s.retired = append(s.retired, seg)
+	}
+}
+
+// reclaim 解除映射並刪除已退役的段，在每次彈出操作開始時呼叫
+func (s *segmentStore) reclaim() {
+	for i, seg := range s.retired {
+		unmapSegment(seg.path, seg.data)
+		s.retired[i] = nil
+	}
+	s.retired = s.retired[:0]
+}
+
+// close 解除映射並刪除所有段
+func (s *segmentStore) close() {
+	s.reclaim()
+	for i, seg := range s.active {
+		unmapSegment(seg.path, seg.data)
+		s.active[i] = nil
+	}
+	s.active = s.active[:0]
+	s.cur = nil
+}
+
+// storeChunk 將數據複製到 mmap 段中，失敗時回傳原始數據
+func (cl *ChunkPipe[T]) storeChunk(data []T) ([]T, *segment) {
+	b, ok := any(data).([]byte)
+	if !ok {
+		return data, nil
+	}
+	seg, buf, err := cl.segs.alloc(len(b))
+	if err != nil {
+		return data, nil
+	}
+	copy(buf, b)
+	return any(buf).([]T), seg
+}
+
+// release 釋放被移除的塊所佔用的資源
+func (cl *ChunkPipe[T]) release(c *chunk[T]) {
+	if c.seg != nil {
+		cl.segs.release(c.seg)
+		c.seg = nil
+	}
+	if c.packed != nil {
+		cl.comp.cache.drop(&c.packed[0])
+	}
+}
+
+// detach 返回要交給呼叫者的塊內容，mmap 段中的塊會被複製到堆上，
+// 讓呼叫者持有的切片在段被解除映射後仍然有效
+func (cl *ChunkPipe[T]) detach(c *chunk[T]) []T {
+	val := cl.load(c)
+	if c.seg != nil {
+		val = slices.Clone(val)
+	}
+	return val
+}
+
+// unmapSegments 將仍在 mmap 段中的塊搬到堆上並釋放所有段，需持有寫鎖
+func (cl *ChunkPipe[T]) unmapSegments() {
+	if cl.segs == nil {
+		return
+	}
+	for i := range cl.list {
+		c := &cl.list[i]
+		if c.seg != nil {
+			c.val = slices.Clone(c.val)
+			cl.segs.release(c.seg)
+			c.seg = nil
+		}
+	}
+	cl.segs.close()
+}
+
+// reclaim 回收上一次彈出操作後不再使用的資源，已關閉時也移除已過期的塊
+func (cl *ChunkPipe[T]) reclaim() {
+	if cl.segs != nil {
+		cl.segs.reclaim()
+	}
+	if cl.closed {
+		cl.expireClosed()
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package chunkpipe
+
+import "errors"
+
+var errMmapUnsupported = errors.New("chunkpipe: mmap segments are not supported on this platform")
+
+func mapSegment(path string, size int) ([]byte, error) {
+	return nil, errMmapUnsupported
+}
+
+func unmapSegment(path string, data []byte) {}
@@ -0,0 +1,86 @@
+package chunkpipe
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestMmapSegments(t *testing.T) {
+	dir := t.TempDir()
+	cp := NewChunkPipe[byte](WithMmapSegments(dir, 16))
+	if cp.Push([]byte("hello")); cp.list[0].seg == nil {
+		t.Skip("mmap segments not supported on this platform")
+	}
+	cp.Push([]byte("world"))
+	cp.Push(bytes.Repeat([]byte("x"), 32))
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 segment files, got %d", len(entries))
+	}
+
+	if v, ok := cp.Get(7); !ok || v != 'r' {
+		t.Errorf("Get(7) = %q, %v", v, ok)
+	}
+
+	if chunk, ok := cp.PopChunkFront(); !ok || string(chunk) != "hello" {
+		t.Errorf("PopChunkFront = %q, %v", chunk, ok)
+	}
+	if v, ok := cp.PopFront(); !ok || v != 'w' {
+		t.Errorf("PopFront = %q, %v", v, ok)
+	}
+	if chunk, ok := cp.PopChunkFront(); !ok || string(chunk) != "orld" {
+		t.Errorf("PopChunkFront = %q, %v", chunk, ok)
+	}
+	if chunk, ok := cp.PopChunkEnd(); !ok || len(chunk) != 32 || chunk[31] != 'x' {
+		t.Errorf("PopChunkEnd = %q, %v", chunk, ok)
+	}
+
+	// 段在下一次彈出操作時才會被回收
+	cp.PopChunkFront()
+	entries, _ = os.ReadDir(dir)
+	if len(entries) != 0 {
+		t.Errorf("expected segment files to be removed, got %d", len(entries))
+	}
+}
+
+func TestMmapViewsOutliveSegments(t *testing.T) {
+	dir := t.TempDir()
+	cp := NewChunkPipe[byte](WithMmapSegments(dir, 16))
+	if cp.Push([]byte("hello")); cp.list[0].seg == nil {
+		t.Skip("mmap segments not supported on this platform")
+	}
+	cp.Push(bytes.Repeat([]byte("y"), 16))
+	chunks := cp.snapshot()
+	a, _ := cp.PopChunkFront()
+	cp.PopChunkFront()
+	cp.PopChunkFront()
+
+	// 第一個段已被解除映射，先前取得的切片仍然可以讀取
+	if string(a) != "hello" || string(chunks[0]) != "hello" {
+		t.Errorf("views after unmap = %q, %q", a, chunks[0])
+	}
+}
+
+func TestMmapClose(t *testing.T) {
+	dir := t.TempDir()
+	cp := NewChunkPipe[byte](WithMmapSegments(dir, 16))
+	if cp.Push([]byte("hello")); cp.list[0].seg == nil {
+		t.Skip("mmap segments not supported on this platform")
+	}
+	cp.Push(bytes.Repeat([]byte("z"), 20))
+	cp.Close()
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 0 {
+		t.Errorf("expected segment files to be removed on Close, got %d", len(entries))
+	}
+	// 關閉後數據仍可讀取
+	if chunk, ok := cp.PopChunkFront(); !ok || string(chunk) != "hello" {
+		t.Errorf("PopChunkFront after Close = %q, %v", chunk, ok)
+	}
+	if st := cp.Stats(); st.Len != 20 {
+		t.Errorf("Len = %d, want 20", st.Len)
+	}
+}
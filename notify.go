@@ -0,0 +1,43 @@
+package chunkpipe
+
+// Close 標記 ChunkPipe 不會再有數據插入，之後的 Push 會被忽略，需要得知插入失敗時請使用 TryPush（返回 ErrClosed）。
+// 已有的數據仍可讀取與彈出，正在等待新數據的讀取者會被喚醒。
+// 存放在 mmap 段中的塊會被搬到堆上並釋放所有段。
+// 啟用 TTL 時會停止移除過期塊的背景 goroutine，並等待進行中的 OnExpire 返回。
+func (cl *ChunkPipe[T]) Close() error {
+	cl.lock()
+	cl.closed = true
+	cl.signal()
+	cl.unmapSegments()
+	stopped := cl.stopReaper()
+	cl.mu.Unlock()
+
+	if stopped != nil {
+		<-stopped
+	}
+	return nil
+}
+
+// Closed 回報 ChunkPipe 是否已被關閉
+func (cl *ChunkPipe[T]) Closed() bool {
+	cl.rlock()
+	defer cl.mu.RUnlock()
+	return cl.closed
+}
+
+// waitChan 返回在下一次插入數據或 Close 時關閉的 channel，需持有寫鎖。
+// 呼叫者應先檢查 closed，避免在已關閉的 ChunkPipe 上等待。
+func (cl *ChunkPipe[T]) waitChan() <-chan struct{} {
+	if cl.notify == nil {
+		cl.notify = make(chan struct{})
+	}
+	return cl.notify
+}
+
+// signal 喚醒所有等待新數據的讀取者，需持有寫鎖
+func (cl *ChunkPipe[T]) signal() {
+	if cl.notify != nil {
+		close(cl.notify)
+		cl.notify = nil
+	}
+}
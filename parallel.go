@@ -0,0 +1,95 @@
+package chunkpipe
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+)
+
+// ParallelForEachChunk 將 ChunkPipe 快照中的每個塊分派給最多 workers 個 goroutine 執行 fn。
+// workers 小於等於 0 時使用 GOMAXPROCS。第一個錯誤會取消傳給其餘 fn 的 ctx，
+// 尚未開始的塊不再處理；返回值以 errors.Join 合併所有 fn 返回的錯誤。
+func ParallelForEachChunk[T any](ctx context.Context, cp *ChunkPipe[T], workers int, fn func(ctx context.Context, chunk []T) error) error {
+	chunks := cp.snapshot()
+	return parallelChunks(ctx, len(chunks), workers, func(ctx context.Context, i int) error {
+		return fn(ctx, chunks[i])
+	})
+}
+
+// ParallelMap 並行地對每個塊套用 f，並按原本的塊順序組成新的 ChunkPipe。
+// 錯誤處理與 ParallelForEachChunk 相同，發生錯誤時返回 nil。
+func ParallelMap[T, U any](ctx context.Context, cp *ChunkPipe[T], workers int, f func(ctx context.Context, chunk []T) ([]U, error)) (*ChunkPipe[U], error) {
+	chunks := cp.snapshot()
+	results := make([][]U, len(chunks))
+	err := parallelChunks(ctx, len(chunks), workers, func(ctx context.Context, i int) error {
+		out, err := f(ctx, chunks[i])
+		results[i] = out
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ret := NewChunkPipe[U]()
+	for _, out := range results {
+		ret.Push(out)
+	}
+	return ret, nil
+}
+
+// parallelChunks 以有上限的工作池對 0..n-1 執行 fn
+func parallelChunks(parent context.Context, n, workers int, fn func(ctx context.Context, i int) error) error {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > n {
+		workers = n
+	}
+
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	var (
+		mu   sync.Mutex
+		errs []error
+		wg   sync.WaitGroup
+	)
+	jobs := make(chan int)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if ctx.Err() != nil {
+					continue
+				}
+				if err := fn(ctx, i); err != nil {
+					mu.Lock()
+					// 因前一個錯誤而被取消的工作不再重複回報
+					if len(errs) == 0 || !errors.Is(err, context.Canceled) {
+						errs = append(errs, err)
+					}
+					mu.Unlock()
+					cancel()
+				}
+			}
+		}()
+	}
+
+feed:
+	for i := 0; i < n; i++ {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if len(errs) != 0 {
+		return errors.Join(errs...)
+	}
+	return parent.Err()
+}
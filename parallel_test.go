@@ -0,0 +1,72 @@
+package chunkpipe
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+func TestParallel(t *testing.T) {
+	cp := NewChunkPipe[int]()
+	for i := 0; i < 100; i++ {
+		cp.Push([]int{i, i + 1})
+	}
+
+	t.Run("ForEachChunk", func(t *testing.T) {
+		var sum atomic.Int64
+		err := ParallelForEachChunk(context.Background(), cp, 4, func(ctx context.Context, c []int) error {
+			for _, v := range c {
+				sum.Add(int64(v))
+			}
+			return nil
+		})
+		if err != nil || sum.Load() != 10000 {
+			t.Errorf("sum = %d, err = %v", sum.Load(), err)
+		}
+	})
+
+	t.Run("MapOrder", func(t *testing.T) {
+		got, err := ParallelMap(context.Background(), cp, 8, func(ctx context.Context, c []int) ([]int, error) {
+			return []int{c[0] * 2}, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := Map(cp, func(c int) int { return c * 2 }).ValueSlice()
+		for i, v := range got.ValueSlice() {
+			if v != want[i*2] {
+				t.Fatalf("chunk %d = %d, want %d", i, v, want[i*2])
+			}
+		}
+	})
+
+	t.Run("ErrorCancels", func(t *testing.T) {
+		boom := errors.New("boom")
+		var calls atomic.Int64
+		_, err := ParallelMap(context.Background(), cp, 2, func(ctx context.Context, c []int) ([]int, error) {
+			calls.Add(1)
+			if c[0] == 0 {
+				return nil, boom
+			}
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+		if !errors.Is(err, boom) {
+			t.Errorf("err = %v, want boom", err)
+		}
+		if calls.Load() >= 100 {
+			t.Errorf("expected remaining chunks to be skipped, got %d calls", calls.Load())
+		}
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		got, err := ParallelMap(context.Background(), NewChunkPipe[int](), 0, func(ctx context.Context, c []int) ([]int, error) {
+			return c, nil
+		})
+		if err != nil || !reflect.DeepEqual(got.ValueSlice(), []int{}) {
+			t.Errorf("got %v, %v", got.ValueSlice(), err)
+		}
+	})
+}
@@ -0,0 +1,69 @@
+package chunkpipe
+
+// Front 返回第一個元素但不移除
+func (cl *ChunkPipe[T]) Front() (T, bool) {
+	cl.rlock()
+	defer cl.mu.RUnlock()
+
+	if len(cl.list) == 0 {
+		var zero T
+		return zero, false
+	}
+	return cl.load(&cl.list[0])[0], true
+}
+
+// Back 返回最後一個元素但不移除
+func (cl *ChunkPipe[T]) Back() (T, bool) {
+	cl.rlock()
+	defer cl.mu.RUnlock()
+
+	listLen := len(cl.list)
+	if listLen == 0 {
+		var zero T
+		return zero, false
+	}
+	val := cl.load(&cl.list[listLen-1])
+	return val[len(val)-1], true
+}
+
+// PeekChunkFront 返回第一個塊但不移除
+func (cl *ChunkPipe[T]) PeekChunkFront() ([]T, bool) {
+	cl.rlock()
+	defer cl.mu.RUnlock()
+
+	if len(cl.list) == 0 {
+		return nil, false
+	}
+	return cl.detach(&cl.list[0]), true
+}
+
+// PeekChunkEnd 返回最後一個塊但不移除
+func (cl *ChunkPipe[T]) PeekChunkEnd() ([]T, bool) {
+	cl.rlock()
+	defer cl.mu.RUnlock()
+
+	listLen := len(cl.list)
+	if listLen == 0 {
+		return nil, false
+	}
+	return cl.detach(&cl.list[listLen-1]), true
+}
+
+// PeekN 以塊視圖返回開頭最多 n 個元素但不移除，最後一個視圖可能只是塊的一部分。
+// 返回的視圖與 ChunkPipe 共用記憶體，不應修改。
+func (cl *ChunkPipe[T]) PeekN(n int) [][]T {
+	cl.rlock()
+	defer cl.mu.RUnlock()
+
+	var ret [][]T
+	list := cl.list
+	for i := 0; i < len(list) && n > 0; i++ {
+		val := cl.detach(&list[i])
+		if len(val) > n {
+			val = val[:n:n]
+		}
+		ret = append(ret, val)
+		n -= len(val)
+	}
+	return ret
+}
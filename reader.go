@@ -0,0 +1,87 @@
+package chunkpipe
+
+import (
+	"errors"
+	"io"
+)
+
+var (
+	errNegativeOffset = errors.New("chunkpipe: negative offset")
+	errInvalidWhence  = errors.New("chunkpipe: invalid whence")
+)
+
+// Reader 是 ChunkPipe[byte] 上不會取出數據的讀取游標，實作 io.ReaderAt 與 io.ReadSeeker。
+// 位置相對於目前的頭部，其他操作從頭部移除數據後位置也會隨之改變。
+type Reader struct {
+	pipe *ChunkPipe[byte]
+	pos  int64
+}
+
+// NewReader 返回從 cp 頭部開始讀取的 Reader
+func NewReader(cp *ChunkPipe[byte]) *Reader {
+	return &Reader{pipe: cp}
+}
+
+// Size 返回 ChunkPipe 目前的位元組數
+func (r *Reader) Size() int64 {
+	cp := r.pipe
+	cp.rlock()
+	defer cp.mu.RUnlock()
+	return int64(cp.size())
+}
+
+// ReadAt 將 [off, off+len(p)) 的位元組複製到 p，可以跨越塊邊界，以 O(log 塊數) 定位起點
+func (r *Reader) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errNegativeOffset
+	}
+
+	cp := r.pipe
+	cp.rlock()
+	defer cp.mu.RUnlock()
+
+	if off >= int64(cp.size()) {
+		return 0, io.EOF
+	}
+
+	n := 0
+	list := cp.list
+	i := cp.locate(int(off))
+	skip := int(off) + cp.offset - cp.chunkStart(i)
+	for ; i < len(list) && n < len(p); i++ {
+		n += copy(p[n:], cp.load(&list[i])[skip:])
+		skip = 0
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// Read 從目前位置讀取並前進，不會取出 ChunkPipe 中的數據
+func (r *Reader) Read(p []byte) (int, error) {
+	n, err := r.ReadAt(p, r.pos)
+	r.pos += int64(n)
+	if err == io.EOF && n > 0 {
+		err = nil
+	}
+	return n, err
+}
+
+// Seek 設定下一次 Read 的位置，實作 io.Seeker
+func (r *Reader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.pos
+	case io.SeekEnd:
+		offset += r.Size()
+	default:
+		return 0, errInvalidWhence
+	}
+	if offset < 0 {
+		return 0, errNegativeOffset
+	}
+	r.pos = offset
+	return offset, nil
+}
@@ -0,0 +1,74 @@
+package chunkpipe
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestReader(t *testing.T) {
+	cp := newBytePipe("0123", "4", "56789", "abcdef")
+	cp.PopFront()
+	r := NewReader(cp)
+
+	t.Run("ReadAt", func(t *testing.T) {
+		p := make([]byte, 6)
+		if n, err := r.ReadAt(p, 2); n != 6 || err != nil || string(p) != "345678" {
+			t.Errorf("ReadAt = %d, %v, %q", n, err, p)
+		}
+		if n, err := r.ReadAt(p, 12); n != 3 || err != io.EOF || string(p[:n]) != "def" {
+			t.Errorf("ReadAt at tail = %d, %v, %q", n, err, p[:n])
+		}
+		if _, err := r.ReadAt(p, 15); err != io.EOF {
+			t.Errorf("ReadAt past end = %v", err)
+		}
+	})
+
+	t.Run("Seek", func(t *testing.T) {
+		if pos, err := r.Seek(-4, io.SeekEnd); pos != 11 || err != nil {
+			t.Errorf("Seek = %d, %v", pos, err)
+		}
+		rest, err := io.ReadAll(r)
+		if err != nil || string(rest) != "cdef" {
+			t.Errorf("ReadAll = %q, %v", rest, err)
+		}
+		if _, err := r.Seek(-1, io.SeekStart); err == nil {
+			t.Error("Seek to negative position should fail")
+		}
+		if cp.size() != 15 {
+			t.Errorf("Reader should not consume data, size = %d", cp.size())
+		}
+	})
+
+	t.Run("Zip", func(t *testing.T) {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		w, _ := zw.Create("hello.txt")
+		w.Write([]byte("hello, chunkpipe"))
+		zw.Close()
+
+		data := buf.Bytes()
+		zp := NewChunkPipe[byte]()
+		for len(data) > 0 {
+			n := min(len(data), 7)
+			zp.Push(data[:n])
+			data = data[n:]
+		}
+
+		r := NewReader(zp)
+		zr, err := zip.NewReader(r, r.Size())
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, err := zr.File[0].Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		content, _ := io.ReadAll(f)
+		if string(content) != "hello, chunkpipe" {
+			t.Errorf("zip content = %q", content)
+		}
+	})
+}
@@ -0,0 +1,81 @@
+package chunkpipe
+
+import "slices"
+
+// Reverse 反轉塊的順序與每個塊的內容。
+// 塊的內容會被就地修改，與 Push 傳入的切片共用的內容也會受影響；與 Clone 共用的塊會先被複製。
+func (cl *ChunkPipe[T]) Reverse() *ChunkPipe[T] {
+	cl.lock()
+	defer cl.mu.Unlock()
+
+	list := cl.list
+	for i := range list {
+		cl.own(&list[i])
+		slices.Reverse(list[i].val)
+	}
+	slices.Reverse(list)
+
+	lens := make([]int, len(list))
+	for i := range list {
+		lens[i] = len(list[i].val)
+	}
+	cl.reindex(lens)
+	return cl
+}
+
+// Rotate 將開頭的 k 個元素移到尾部，k 為負數時將尾部的 -k 個元素移到開頭。
+// 只會切分邊界所在的塊並重新排列塊，不會複製數據。
+func (cl *ChunkPipe[T]) Rotate(k int) *ChunkPipe[T] {
+	cl.lock()
+	defer cl.mu.Unlock()
+
+	n := cl.size()
+	if n == 0 {
+		return cl
+	}
+	k %= n
+	if k < 0 {
+		k += n
+	}
+	if k == 0 {
+		return cl
+	}
+
+	i := cl.splitAt(k)
+	lens := cl.chunkLens()
+	list := make([]chunk[T], 0, cap(cl.list))
+	list = append(list, cl.list[i:]...)
+	list = append(list, cl.list[:i]...)
+	cl.list = list
+	cl.reindex(append(lens[i:len(lens):len(lens)], lens[:i]...))
+	return cl
+}
+
+// Swap 交換索引 i 與 j 的元素，索引超出範圍時返回 false。
+// 塊的內容會被就地修改，與 Push 傳入的切片共用的內容也會受影響；與 Clone 共用的塊會先被複製。
+func (cl *ChunkPipe[T]) Swap(i, j int) bool {
+	cl.lock()
+	defer cl.mu.Unlock()
+
+	n := cl.size()
+	if i < 0 || j < 0 || i >= n || j >= n {
+		return false
+	}
+
+	ci, cj := cl.locate(i), cl.locate(j)
+	cl.own(&cl.list[ci])
+	cl.own(&cl.list[cj])
+	a := &cl.list[ci].val[i+cl.offset-cl.chunkStart(ci)]
+	b := &cl.list[cj].val[j+cl.offset-cl.chunkStart(cj)]
+	*a, *b = *b, *a
+	return true
+}
+
+// own 在就地修改塊的內容前呼叫，解壓縮並複製與 Clone 共用的內容，需持有寫鎖
+func (cl *ChunkPipe[T]) own(c *chunk[T]) {
+	cl.unpack(c)
+	if c.shared {
+		c.val = slices.Clone(c.val)
+		c.shared = false
+	}
+}
@@ -0,0 +1,69 @@
+package chunkpipe
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReorder(t *testing.T) {
+	newPipe := func() *ChunkPipe[int] {
+		cp := NewChunkPipe[int]()
+		cp.Push([]int{0, 1, 2}).Push([]int{3}).Push([]int{4, 5, 6, 7})
+		cp.PopFront()
+		return cp
+	}
+	check := func(t *testing.T, cp *ChunkPipe[int], want []int) {
+		t.Helper()
+		if got := cp.ValueSlice(); !reflect.DeepEqual(got, want) {
+			t.Errorf("ValueSlice = %v, want %v", got, want)
+		}
+		for i, v := range want {
+			if got, ok := cp.Get(i); !ok || got != v {
+				t.Errorf("Get(%d) = %v, %v, want %v", i, got, ok, v)
+			}
+		}
+		if _, ok := cp.Get(len(want)); ok {
+			t.Errorf("Get(%d) should be out of range", len(want))
+		}
+		var iterated []int
+		iter := cp.ValueIter()
+		for iter.Next() {
+			iterated = append(iterated, iter.V())
+		}
+		if !reflect.DeepEqual(iterated, want) {
+			t.Errorf("ValueIter = %v, want %v", iterated, want)
+		}
+	}
+
+	t.Run("Reverse", func(t *testing.T) {
+		cp := newPipe().Reverse()
+		check(t, cp, []int{7, 6, 5, 4, 3, 2, 1})
+		if got := cp.ChunkSlice(); !reflect.DeepEqual(got, [][]int{{7, 6, 5, 4}, {3}, {2, 1}}) {
+			t.Errorf("ChunkSlice = %v", got)
+		}
+	})
+
+	t.Run("Rotate", func(t *testing.T) {
+		check(t, newPipe().Rotate(3), []int{4, 5, 6, 7, 1, 2, 3})
+		check(t, newPipe().Rotate(4), []int{5, 6, 7, 1, 2, 3, 4})
+		check(t, newPipe().Rotate(-2), []int{6, 7, 1, 2, 3, 4, 5})
+		check(t, newPipe().Rotate(7), []int{1, 2, 3, 4, 5, 6, 7})
+
+		cp := newPipe().Rotate(1)
+		if v, ok := cp.PopEnd(); !ok || v != 1 {
+			t.Errorf("PopEnd = %v, %v", v, ok)
+		}
+		check(t, cp, []int{2, 3, 4, 5, 6, 7})
+	})
+
+	t.Run("Swap", func(t *testing.T) {
+		cp := newPipe()
+		if !cp.Swap(0, 6) || !cp.Swap(2, 3) {
+			t.Error("Swap should succeed")
+		}
+		if cp.Swap(0, 7) {
+			t.Error("Swap should fail for out of range index")
+		}
+		check(t, cp, []int{7, 2, 4, 3, 5, 6, 1})
+	})
+}
@@ -0,0 +1,179 @@
+package chunkpipe
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"slices"
+)
+
+// 連續返回空 token 而沒有前進的次數上限
+const maxConsecutiveEmptyTokens = 100
+
+// Scanner 以 bufio.SplitFunc 從 ChunkPipe[byte] 的頭部取出 token。
+// token 位於單一塊內時返回該塊的視圖，跨越塊時才會複製。
+// token 在下一次呼叫 Scan 之前有效。
+type Scanner struct {
+	pipe         *ChunkPipe[byte]
+	split        bufio.SplitFunc
+	ctx          context.Context
+	maxTokenSize int
+	buf          []byte
+	token        []byte
+	err          error
+	empties      int
+	done         bool
+}
+
+// NewScanner 返回從 cp 讀取的 Scanner，預設以 bufio.ScanLines 分割
+func NewScanner(cp *ChunkPipe[byte]) *Scanner {
+	return &Scanner{
+		pipe:         cp,
+		split:        bufio.ScanLines,
+		maxTokenSize: bufio.MaxScanTokenSize,
+	}
+}
+
+// Split 設定分割函式，必須在第一次呼叫 Scan 之前設定
+func (s *Scanner) Split(split bufio.SplitFunc) {
+	s.split = split
+}
+
+// Buffer 設定跨塊 token 的最大長度
+func (s *Scanner) Buffer(max int) {
+	s.maxTokenSize = max
+}
+
+// WaitForMore 讓 Scan 在數據不足時等待新數據插入，而不是將現有數據視為結尾。
+// 等待會在 ChunkPipe 被關閉或 ctx 結束時停止。
+func (s *Scanner) WaitForMore(ctx context.Context) {
+	s.ctx = ctx
+}
+
+// Scan 取出下一個 token，沒有更多 token 或發生錯誤時返回 false
+func (s *Scanner) Scan() bool {
+	for !s.done {
+		wait := s.scan()
+		if wait == nil {
+			return !s.done || s.token != nil
+		}
+		select {
+		case <-wait:
+		case <-s.ctx.Done():
+			s.token = nil
+			s.err = s.ctx.Err()
+			s.done = true
+		}
+	}
+	s.token = nil
+	return false
+}
+
+// Bytes 返回最近一次 Scan 取得的 token
+func (s *Scanner) Bytes() []byte {
+	return s.token
+}
+
+// Text 以字串返回最近一次 Scan 取得的 token
+func (s *Scanner) Text() string {
+	return string(s.token)
+}
+
+// Err 返回掃描時發生的錯誤，正常結束時返回 nil
+func (s *Scanner) Err() error {
+	return s.err
+}
+
+// scan 嘗試取出一個 token，需要等待新數據時返回等待用的 channel
+func (s *Scanner) scan() <-chan struct{} {
+	cp := s.pipe
+	var pe pendingEvent
+	defer pe.publish()
+	cp.lock()
+	defer cp.mu.Unlock()
+	removed := 0
+	defer func() {
+		pe = cp.event(EventPopFront, removed)
+	}()
+	cp.reclaim()
+
+	s.token = nil
+	atEOF := s.ctx == nil || cp.closed
+	for {
+		list := cp.list
+		// 先只看第一個塊，不足時每次將視窗擴大一倍並複製
+		for n := 1; ; n *= 2 {
+			n = min(n, len(list))
+			var data []byte
+			if n <= 1 {
+				if n == 1 {
+					data = cp.load(&list[0])
+				}
+			} else {
+				s.buf = s.buf[:0]
+				for i := 0; i < n; i++ {
+					s.buf = append(s.buf, cp.load(&list[i])...)
+				}
+				data = s.buf
+			}
+			all := n == len(list)
+
+			advance, token, err := s.split(data, atEOF && all)
+			if err != nil {
+				if err == bufio.ErrFinalToken {
+					s.token = token
+				} else {
+					s.err = err
+				}
+				s.done = true
+				return nil
+			}
+			if advance < 0 || advance > len(data) {
+				s.err = bufio.ErrNegativeAdvance
+				if advance > 0 {
+					s.err = bufio.ErrAdvanceTooFar
+				}
+				s.done = true
+				return nil
+			}
+
+			if advance > 0 || token != nil {
+				if n == 1 && list[0].seg != nil && token != nil {
+					// token 可能指向 mmap 段，段在彈出後可能被解除映射
+					token = slices.Clone(token)
+				}
+				_, k := cp.removeFront(advance, false)
+				removed += k
+				if token == nil {
+					break
+				}
+				if advance == 0 {
+					s.empties++
+					if s.empties > maxConsecutiveEmptyTokens {
+						s.err = io.ErrNoProgress
+						s.done = true
+						return nil
+					}
+				} else {
+					s.empties = 0
+				}
+				s.token = token
+				return nil
+			}
+
+			// 等待更多數據之前也要檢查，否則緩衝區會無限增長
+			if len(data) >= s.maxTokenSize {
+				s.err = bufio.ErrTooLong
+				s.done = true
+				return nil
+			}
+			if all {
+				if atEOF {
+					s.done = true
+					return nil
+				}
+				return cp.waitChan()
+			}
+		}
+	}
+}
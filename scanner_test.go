@@ -0,0 +1,114 @@
+package chunkpipe
+
+import (
+	"bufio"
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestScanner(t *testing.T) {
+	t.Run("Lines", func(t *testing.T) {
+		first := []byte("one\ntw")
+		cp := NewChunkPipe[byte]()
+		cp.Push(first).Push([]byte("o\r")).Push([]byte("\nthree"))
+
+		s := NewScanner(cp)
+		var lines []string
+		for s.Scan() {
+			if len(lines) == 0 && &s.Bytes()[0] != &first[0] {
+				t.Error("token inside a single chunk should be a view of the chunk")
+			}
+			lines = append(lines, s.Text())
+		}
+		if err := s.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if want := []string{"one", "two", "three"}; !reflect.DeepEqual(lines, want) {
+			t.Errorf("lines = %q, want %q", lines, want)
+		}
+		if cp.size() != 0 {
+			t.Errorf("size = %d, want 0", cp.size())
+		}
+	})
+
+	t.Run("Words", func(t *testing.T) {
+		cp := newBytePipe("  ", " al", "pha  be", "ta ", "gamma")
+		s := NewScanner(cp)
+		s.Split(bufio.ScanWords)
+		var words []string
+		for s.Scan() {
+			words = append(words, s.Text())
+		}
+		if want := []string{"alpha", "beta", "gamma"}; !reflect.DeepEqual(words, want) {
+			t.Errorf("words = %q, want %q", words, want)
+		}
+	})
+
+	t.Run("TooLong", func(t *testing.T) {
+		cp := newBytePipe("abc", "def", "ghi")
+		s := NewScanner(cp)
+		s.Buffer(4)
+		if s.Scan() {
+			t.Error("Scan should fail")
+		}
+		if s.Err() != bufio.ErrTooLong {
+			t.Errorf("Err = %v, want ErrTooLong", s.Err())
+		}
+	})
+
+	t.Run("TooLongWaiting", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		cp := NewChunkPipe[byte]()
+		cp.Push(make([]byte, 100))
+		s := NewScanner(cp)
+		s.Buffer(10)
+		s.WaitForMore(ctx)
+		if s.Scan() {
+			t.Error("Scan should fail")
+		}
+		if s.Err() != bufio.ErrTooLong {
+			t.Errorf("Err = %v, want ErrTooLong", s.Err())
+		}
+	})
+
+	t.Run("WaitForMore", func(t *testing.T) {
+		cp := newBytePipe("hel")
+		s := NewScanner(cp)
+		s.WaitForMore(context.Background())
+
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			cp.Push([]byte("lo\nwor"))
+			time.Sleep(10 * time.Millisecond)
+			cp.Push([]byte("ld"))
+			cp.Close()
+		}()
+
+		var lines []string
+		for s.Scan() {
+			lines = append(lines, s.Text())
+		}
+		if want := []string{"hello", "world"}; !reflect.DeepEqual(lines, want) {
+			t.Errorf("lines = %q, want %q", lines, want)
+		}
+		if cp.Push([]byte("ignored")); cp.size() != 0 {
+			t.Error("Push after Close should be ignored")
+		}
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		s := NewScanner(newBytePipe("partial"))
+		s.WaitForMore(ctx)
+		if s.Scan() {
+			t.Error("Scan should not return an incomplete line")
+		}
+		if s.Err() != context.DeadlineExceeded {
+			t.Errorf("Err = %v, want DeadlineExceeded", s.Err())
+		}
+	})
+}
@@ -0,0 +1,95 @@
+package chunkpipe
+
+import "slices"
+
+// chunkStart 返回第 i 個塊第一個元素的絕對偏移，需持有讀鎖
+func (cl *ChunkPipe[T]) chunkStart(i int) int {
+	if i == 0 {
+		return cl.offset
+	}
+	return cl.list[i-1].off
+}
+
+// IndexFunc 返回第一個滿足 pred 的元素索引，找不到時返回 -1。
+// 搜尋函式在讀鎖下逐塊進行，傳入的函式不可修改 cp。
+func IndexFunc[T any](cp *ChunkPipe[T], pred func(T) bool) int {
+	cp.rlock()
+	defer cp.mu.RUnlock()
+
+	list := cp.list
+	for i := range list {
+		if j := slices.IndexFunc(cp.load(&list[i]), pred); j >= 0 {
+			return cp.chunkStart(i) - cp.offset + j
+		}
+	}
+	return -1
+}
+
+// LastIndexFunc 從尾部開始逐塊搜尋，返回最後一個滿足 pred 的元素索引，找不到時返回 -1
+func LastIndexFunc[T any](cp *ChunkPipe[T], pred func(T) bool) int {
+	cp.rlock()
+	defer cp.mu.RUnlock()
+
+	list := cp.list
+	for i := len(list) - 1; i >= 0; i-- {
+		val := cp.load(&list[i])
+		for j := len(val) - 1; j >= 0; j-- {
+			if pred(val[j]) {
+				return cp.chunkStart(i) - cp.offset + j
+			}
+		}
+	}
+	return -1
+}
+
+// index 返回第一個等於 v 的元素索引，找不到時返回 -1
+func index[T comparable](cp *ChunkPipe[T], v T) int {
+	cp.rlock()
+	defer cp.mu.RUnlock()
+
+	list := cp.list
+	for i := range list {
+		if j := slices.Index(cp.load(&list[i]), v); j >= 0 {
+			return cp.chunkStart(i) - cp.offset + j
+		}
+	}
+	return -1
+}
+
+// Contains 回報 ChunkPipe 是否包含 v
+func Contains[T comparable](cp *ChunkPipe[T], v T) bool {
+	return index(cp, v) >= 0
+}
+
+// BinarySearchFunc 在已按 cmp 排序的 ChunkPipe 中搜尋 target，
+// 返回找到的位置或應插入的位置，以及是否找到，語意與 slices.BinarySearchFunc 相同。
+// 先以每個塊的首尾元素對塊進行二分搜尋，再於目標塊內搜尋。
+func BinarySearchFunc[T, E any](cp *ChunkPipe[T], target E, cmp func(T, E) int) (int, bool) {
+	cp.rlock()
+	defer cp.mu.RUnlock()
+
+	list := cp.list
+	// 找出第一個尾元素不小於 target 的塊
+	l, r := 0, len(list)
+	for l < r {
+		m := int(uint(l+r) >> 1)
+		val := cp.load(&list[m])
+		if cmp(val[len(val)-1], target) < 0 {
+			l = m + 1
+		} else {
+			r = m
+		}
+	}
+	if l == len(list) {
+		return cp.size(), false
+	}
+
+	start := cp.chunkStart(l) - cp.offset
+	val := cp.load(&list[l])
+	if c := cmp(val[0], target); c >= 0 {
+		// 目標不大於塊的首元素，無需在塊內搜尋
+		return start, c == 0
+	}
+	j, found := slices.BinarySearchFunc(val, target, cmp)
+	return start + j, found
+}
@@ -0,0 +1,53 @@
+package chunkpipe
+
+import (
+	"cmp"
+	"testing"
+)
+
+func TestSearch(t *testing.T) {
+	cp := NewChunkPipe[int]()
+	cp.Push([]int{0, 1, 2}).Push([]int{4, 6}).Push([]int{6, 8, 10, 12})
+	cp.PopFront()
+	// 內容：1 2 | 4 6 | 6 8 10 12
+
+	even := func(v int) bool { return v%2 == 0 }
+	if got := IndexFunc(cp, even); got != 1 {
+		t.Errorf("IndexFunc = %d, want 1", got)
+	}
+	if got := LastIndexFunc(cp, func(v int) bool { return v == 6 }); got != 4 {
+		t.Errorf("LastIndexFunc = %d, want 4", got)
+	}
+	if got := IndexFunc(cp, func(v int) bool { return v > 100 }); got != -1 {
+		t.Errorf("IndexFunc = %d, want -1", got)
+	}
+	if !Contains(cp, 10) || Contains(cp, 3) {
+		t.Error("Contains mismatch")
+	}
+
+	tests := []struct {
+		target int
+		want   int
+		found  bool
+	}{
+		{0, 0, false},
+		{1, 0, true},
+		{3, 2, false},
+		{4, 2, true},
+		{6, 3, true},
+		{7, 5, false},
+		{12, 7, true},
+		{13, 8, false},
+	}
+	for _, tt := range tests {
+		got, found := BinarySearchFunc(cp, tt.target, cmp.Compare[int])
+		if got != tt.want || found != tt.found {
+			t.Errorf("BinarySearchFunc(%d) = %d, %v, want %d, %v", tt.target, got, found, tt.want, tt.found)
+		}
+		if found {
+			if v, _ := cp.Get(got); v != tt.target {
+				t.Errorf("Get(%d) = %d, want %d", got, v, tt.target)
+			}
+		}
+	}
+}
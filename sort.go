@@ -0,0 +1,185 @@
+package chunkpipe
+
+import (
+	"cmp"
+	"container/heap"
+	"context"
+	"slices"
+)
+
+// SortFunc 依 cmp 排序 ChunkPipe 的內容，保留原本各塊的長度。排序不保證穩定。
+// 各塊保留原本位置的中繼資料，包括到期時間。
+func SortFunc[T any](cp *ChunkPipe[T], cmp func(a, b T) int) {
+	ParallelSortFunc(cp, 1, cmp)
+}
+
+// ParallelSortFunc 與 SortFunc 相同，但以最多 workers 個 goroutine 並行排序各塊，
+// 再以 k 路合併寫回 ChunkPipe。排序期間持有寫鎖，cmp 不可存取 cp。
+// 各塊會先被複製，不會修改 Push 時傳入的切片。
+func ParallelSortFunc[T any](cp *ChunkPipe[T], workers int, cmp func(a, b T) int) {
+	cp.lock()
+	defer cp.mu.Unlock()
+
+	list := cp.list
+	if len(list) == 0 {
+		return
+	}
+
+	sorted := make([][]T, len(list))
+	for i := range list {
+		sorted[i] = slices.Clone(cp.load(&list[i]))
+	}
+	if workers > 1 {
+		parallelChunks(context.Background(), len(sorted), workers, func(ctx context.Context, i int) error {
+			slices.SortFunc(sorted[i], cmp)
+			return nil
+		})
+	} else {
+		for _, c := range sorted {
+			slices.SortFunc(c, cmp)
+		}
+	}
+
+	sources := make([][][]T, len(sorted))
+	for i, c := range sorted {
+		sources[i] = [][]T{c}
+	}
+	it := newMergeIterator(cmp, sources)
+
+	// 按原本的塊長度重建
+	out := make([][]T, len(sorted))
+	for i, c := range sorted {
+		merged := make([]T, len(c))
+		for j := range merged {
+			it.Next()
+			merged[j] = it.V()
+		}
+		out[i] = merged
+	}
+
+	metas := make([]ChunkMeta, len(list))
+	for i := range list {
+		metas[i] = list[i].meta
+	}
+	lastID := cp.lastID
+	cp.clearChunks()
+	for i, c := range out {
+		var packed []byte
+		if cp.comp != nil {
+			packed = cp.packChunk(c)
+		}
+		cp.appendChunk(c, packed)
+		cp.list[len(cp.list)-1].meta = metas[i]
+	}
+	cp.lastID = lastID
+}
+
+// MergeSorted 返回多個已排序 ChunkPipe 的合併迭代器
+func MergeSorted[T cmp.Ordered](pipes ...*ChunkPipe[T]) *MergeIterator[T] {
+	return MergeSortedFunc(cmp.Compare[T], pipes...)
+}
+
+// MergeSortedFunc 返回多個已依 cmp 排序的 ChunkPipe 的合併迭代器。
+// 迭代器在建立時取得各 ChunkPipe 的塊快照並按需合併，不會取出數據；
+// 相等的元素按 pipes 的順序輸出。
+func MergeSortedFunc[T any](cmp func(a, b T) int, pipes ...*ChunkPipe[T]) *MergeIterator[T] {
+	sources := make([][][]T, len(pipes))
+	for i, cp := range pipes {
+		sources[i] = cp.snapshot()
+	}
+	return newMergeIterator(cmp, sources)
+}
+
+// MergeIterator 依序輸出多個已排序來源合併後的元素
+type MergeIterator[T any] struct {
+	h   mergeHeap[T]
+	cur T
+}
+
+// mergeCursor 是一個來源目前的讀取位置
+type mergeCursor[T any] struct {
+	src    int
+	chunks [][]T
+	ci, vi int
+}
+
+func (c *mergeCursor[T]) head() T {
+	return c.chunks[c.ci][c.vi]
+}
+
+// advance 移到下一個元素，來源耗盡時返回 false
+func (c *mergeCursor[T]) advance() bool {
+	c.vi++
+	for c.ci < len(c.chunks) && c.vi >= len(c.chunks[c.ci]) {
+		c.ci++
+		c.vi = 0
+	}
+	return c.ci < len(c.chunks)
+}
+
+type mergeHeap[T any] struct {
+	cmp     func(a, b T) int
+	cursors []*mergeCursor[T]
+}
+
+func (h *mergeHeap[T]) Len() int { return len(h.cursors) }
+
+func (h *mergeHeap[T]) Less(i, j int) bool {
+	a, b := h.cursors[i], h.cursors[j]
+	if c := h.cmp(a.head(), b.head()); c != 0 {
+		return c < 0
+	}
+	return a.src < b.src
+}
+
+func (h *mergeHeap[T]) Swap(i, j int) {
+	h.cursors[i], h.cursors[j] = h.cursors[j], h.cursors[i]
+}
+
+func (h *mergeHeap[T]) Push(x any) {
+	h.cursors = append(h.cursors, x.(*mergeCursor[T]))
+}
+
+func (h *mergeHeap[T]) Pop() any {
+	n := len(h.cursors) - 1
+	c := h.cursors[n]
+	h.cursors[n] = nil
+	h.cursors = h.cursors[:n]
+	return c
+}
+
+func newMergeIterator[T any](cmp func(a, b T) int, sources [][][]T) *MergeIterator[T] {
+	it := &MergeIterator[T]{
+		h: mergeHeap[T]{cmp: cmp},
+	}
+	for i, chunks := range sources {
+		c := &mergeCursor[T]{src: i, chunks: chunks, vi: -1}
+		if c.advance() {
+			it.h.cursors = append(it.h.cursors, c)
+		}
+	}
+	heap.Init(&it.h)
+	return it
+}
+
+// Next 移到下一個元素，沒有更多元素時返回 false
+func (it *MergeIterator[T]) Next() bool {
+	if it.h.Len() == 0 {
+		var zero T
+		it.cur = zero
+		return false
+	}
+	c := it.h.cursors[0]
+	it.cur = c.head()
+	if c.advance() {
+		heap.Fix(&it.h, 0)
+	} else {
+		heap.Pop(&it.h)
+	}
+	return true
+}
+
+// V 返回目前的元素
+func (it *MergeIterator[T]) V() T {
+	return it.cur
+}
@@ -0,0 +1,69 @@
+package chunkpipe
+
+import (
+	"cmp"
+	"math/rand"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestSortFunc(t *testing.T) {
+	for _, workers := range []int{1, 4} {
+		cp := NewChunkPipe[int]()
+		var all []int
+		var first []int
+		sizes := []int{5, 1, 17, 3, 9}
+		for _, n := range sizes {
+			c := make([]int, n)
+			for i := range c {
+				c[i] = rand.Intn(50)
+			}
+			if first == nil {
+				first = c
+			}
+			all = append(all, c...)
+			cp.Push(c)
+		}
+		orig := slices.Clone(first)
+
+		ParallelSortFunc(cp, workers, cmp.Compare[int])
+
+		slices.Sort(all)
+		if got := cp.ValueSlice(); !reflect.DeepEqual(got, all) {
+			t.Errorf("workers=%d: ValueSlice = %v, want %v", workers, got, all)
+		}
+		for i, c := range cp.ChunkSlice() {
+			if len(c) != sizes[i] {
+				t.Errorf("workers=%d: chunk %d has length %d, want %d", workers, i, len(c), sizes[i])
+			}
+		}
+		if v, ok := cp.Get(len(all) - 1); !ok || v != all[len(all)-1] {
+			t.Errorf("Get after sort = %v, %v", v, ok)
+		}
+		if !reflect.DeepEqual(first, orig) {
+			t.Errorf("workers=%d: pushed slice was modified", workers)
+		}
+	}
+}
+
+func TestMergeSorted(t *testing.T) {
+	a := NewChunkPipe[int]()
+	a.Push([]int{1, 4}).Push([]int{7})
+	b := NewChunkPipe[int]()
+	b.Push([]int{2, 3, 4, 9})
+	empty := NewChunkPipe[int]()
+
+	var got []int
+	it := MergeSorted(a, empty, b)
+	for it.Next() {
+		got = append(got, it.V())
+	}
+	want := []int{1, 2, 3, 4, 4, 7, 9}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MergeSorted = %v, want %v", got, want)
+	}
+	if a.size() != 3 || b.size() != 4 {
+		t.Error("MergeSorted should not consume the pipes")
+	}
+}
@@ -0,0 +1,64 @@
+package chunkpipe
+
+// Stats 是 ChunkPipe 的統計快照
+type Stats struct {
+	// 目前的元素數與塊數
+	Len    int
+	Chunks int
+
+	// 目前以壓縮形式保存的塊數
+	CompressedChunks int
+	// 累計被壓縮的原始位元組數與壓縮後位元組數
+	RawBytes        int64
+	CompressedBytes int64
+	// 壓縮率，即 RawBytes / CompressedBytes，未啟用壓縮時為 0
+	CompressionRatio float64
+	// 解壓縮失敗的次數
+	DecodeErrors int64
+
+	// 以下為累計指標，以 WithoutMetrics 停用時為零值
+	// 增加數據的 Push 次數與各類取出操作的次數
+	Pushes uint64
+	Pops   PopCounts
+	// 累計插入與移除的元素數，不包含放回的元素
+	ElementsIn  uint64
+	ElementsOut uint64
+	// 取出後又被放回頭部的次數與元素數，例如 UnreadRune、Nack、租約逾時，
+	// 以及 ChunkChan 與 ToChan 在 ctx 結束時放回尚未送出的數據
+	Requeues         uint64
+	ElementsRequeued uint64
+	// 插入塊的長度分佈
+	ChunkSize Histogram
+	// 需要等待時，等待寫鎖與讀鎖的時間分佈，單位為秒；未競爭的取得不計入
+	LockWait  Histogram
+	RLockWait Histogram
+}
+
+// Stats 返回 ChunkPipe 目前的統計資訊
+func (cl *ChunkPipe[T]) Stats() Stats {
+	cl.rlock()
+	defer cl.mu.RUnlock()
+
+	st := Stats{
+		Len:    cl.size(),
+		Chunks: len(cl.list),
+	}
+
+	if comp := cl.comp; comp != nil {
+		for i := range cl.list {
+			if cl.list[i].packed != nil {
+				st.CompressedChunks++
+			}
+		}
+		st.RawBytes = comp.rawBytes.Load()
+		st.CompressedBytes = comp.packedBytes.Load()
+		st.DecodeErrors = comp.decodeErrors.Load()
+		if st.CompressedBytes > 0 {
+			st.CompressionRatio = float64(st.RawBytes) / float64(st.CompressedBytes)
+		}
+	}
+	if cl.metrics != nil {
+		cl.metrics.fill(&st)
+	}
+	return st
+}
@@ -3,6 +3,7 @@ package chunkpipe
 import (
 	"runtime"
 	"sync"
+	"time"
 )
 
 type ChunkPipe[T any] struct {
@@ -13,37 +14,78 @@ type ChunkPipe[T any] struct {
 	valueSlicePool sync.Pool
 	chunkSlicePool sync.Pool
 	valueCache     valueCache[T]
+	// 僅在啟用 mmap 段儲存時使用
+	segs *segmentStore
+	// 僅在啟用壓縮時使用
+	comp *compression
+	// MarshalJSON 是否輸出扁平的元素陣列
+	flatJSON bool
+	// 關閉後不再接受新數據
+	closed bool
+	// 等待新數據的讀取者，在插入數據或關閉時被關閉
+	notify chan struct{}
+	// 最近一次 ReadRune 讀出的 rune
+	lastRune *runeState
+	// 已註冊的 Cursor
+	cursors []*Cursor[T]
+	// 租用中的塊，在第一次 Lease 時建立
+	leases *leaseTable[T]
+	// 超過最大租用次數的塊會被移到死信 ChunkPipe
+	deadLetter  *ChunkPipe[T]
+	maxAttempts int
+	// 事件訂閱者，修改時整個替換
+	subs []*subscriber
+	// 以 WithHooks 安裝的鉤子，按安裝順序呼叫
+	hooks []Hooks[T]
+	// 內建指標，以 WithoutMetrics 停用時為 nil
+	metrics *metrics
+	// 最近一次分配的塊 ID
+	lastID uint64
+	// 以 WithTTL 設定的預設存活時間與 WithOnExpire 設定的回呼
+	ttl      time.Duration
+	onExpire func(chunks [][]T)
+	// 移除過期塊的背景 goroutine，在第一個會過期的塊插入時建立
+	reaper *reaper
 }
 
 type chunk[T any] struct {
 	off int
 	val []T
+	// 塊所在的 mmap 段，堆上的塊為 nil
+	seg *segment
+	// 壓縮後的內容，非 nil 時 val 為 nil
+	packed []byte
+	// 壓縮前的長度，僅在 packed 非 nil 時使用
+	rawLen int
+	// val 與 Clone 出的 ChunkPipe 共用，就地修改內容前需要先複製
+	shared bool
+	// 已被租用的次數
+	attempts int
+	// 塊的中繼資料，切分或放回頭部時保留
+	meta ChunkMeta
 }
 
+// Option 用於在建立 ChunkPipe 時調整其行為
+type Option[T any] func(*ChunkPipe[T])
+
 type valueCache[T any] struct {
 	mu    sync.RWMutex
 	cache []*T
 }
 
 // 在 ChunkPipe 結構體中修改 New 函數的返回類型
-func NewChunkPipe[T any]() *ChunkPipe[T] {
+func NewChunkPipe[T any](opts ...Option[T]) *ChunkPipe[T] {
 	cp := &ChunkPipe[T]{
 		list: make([]chunk[T], 0, 4096),
-		chunkSlicePool: sync.Pool{
-			New: func() interface{} {
-				slice := make([][]T, 4096)
-				return &slice // 返回指針
-			},
-		},
-		valueSlicePool: sync.Pool{
-			New: func() interface{} {
-				slice := make([]T, 4096)
-				return &slice // 返回指針
-			},
-		},
 		valueCache: valueCache[T]{
 			cache: make([]*T, 0, 4096),
 		},
+		metrics: newMetrics(),
+	}
+	cp.initPools()
+
+	for _, opt := range opts {
+		opt(cp)
 	}
 
 	go func() {
@@ -54,6 +96,18 @@ func NewChunkPipe[T any]() *ChunkPipe[T] {
 	return cp
 }
 
+// initPools 初始化切片池，零值的 ChunkPipe 在使用前也需要呼叫
+func (cp *ChunkPipe[T]) initPools() {
+	cp.chunkSlicePool.New = func() interface{} {
+		slice := make([][]T, 4096)
+		return &slice // 返回指針
+	}
+	cp.valueSlicePool.New = func() interface{} {
+		slice := make([]T, 4096)
+		return &slice // 返回指針
+	}
+}
+
 // ValueIterator 提供值迭代器
 type ValueIterator[T any] struct {
 	pos  int
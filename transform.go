@@ -0,0 +1,52 @@
+package chunkpipe
+
+// Map 對每個元素套用 f，返回塊邊界相同的新 ChunkPipe
+func Map[T, U any](cp *ChunkPipe[T], f func(T) U) *ChunkPipe[U] {
+	ret := NewChunkPipe[U]()
+	for _, c := range cp.snapshot() {
+		out := make([]U, len(c))
+		for i, v := range c {
+			out[i] = f(v)
+		}
+		ret.Push(out)
+	}
+	return ret
+}
+
+// Filter 返回只包含滿足 keep 的元素的新 ChunkPipe，過濾後為空的塊會被略過
+func Filter[T any](cp *ChunkPipe[T], keep func(T) bool) *ChunkPipe[T] {
+	ret := NewChunkPipe[T]()
+	for _, c := range cp.snapshot() {
+		var out []T
+		for _, v := range c {
+			if keep(v) {
+				out = append(out, v)
+			}
+		}
+		ret.Push(out)
+	}
+	return ret
+}
+
+// FlatMap 將每個元素展開為多個元素，同一塊展開的結果組成新 ChunkPipe 中的一個塊
+func FlatMap[T, U any](cp *ChunkPipe[T], f func(T) []U) *ChunkPipe[U] {
+	ret := NewChunkPipe[U]()
+	for _, c := range cp.snapshot() {
+		var out []U
+		for _, v := range c {
+			out = append(out, f(v)...)
+		}
+		ret.Push(out)
+	}
+	return ret
+}
+
+// MapChunks 對每個塊套用 f，每個結果成為新 ChunkPipe 中的一個塊，空結果會被略過。
+// 傳入 f 的塊與原 ChunkPipe 共用記憶體，不應修改。
+func MapChunks[T, U any](cp *ChunkPipe[T], f func([]T) []U) *ChunkPipe[U] {
+	ret := NewChunkPipe[U]()
+	for _, c := range cp.snapshot() {
+		ret.Push(f(c))
+	}
+	return ret
+}
@@ -0,0 +1,44 @@
+package chunkpipe
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestTransforms(t *testing.T) {
+	cp := NewChunkPipe[int]()
+	cp.Push([]int{1, 2, 3}).Push([]int{4}).Push([]int{5, 6})
+
+	t.Run("Map", func(t *testing.T) {
+		got := Map(cp, strconv.Itoa).ChunkSlice()
+		want := [][]string{{"1", "2", "3"}, {"4"}, {"5", "6"}}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Map = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("Filter", func(t *testing.T) {
+		got := Filter(cp, func(v int) bool { return v%2 == 1 }).ChunkSlice()
+		want := [][]int{{1, 3}, {5}}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Filter = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("FlatMap", func(t *testing.T) {
+		got := FlatMap(cp, func(v int) []int { return []int{v, v} }).ChunkSlice()
+		want := [][]int{{1, 1, 2, 2, 3, 3}, {4, 4}, {5, 5, 6, 6}}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("FlatMap = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("MapChunks", func(t *testing.T) {
+		got := MapChunks(cp, func(c []int) []int { return []int{len(c)} }).ChunkSlice()
+		want := [][]int{{3}, {1}, {2}}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("MapChunks = %v, want %v", got, want)
+		}
+	})
+}
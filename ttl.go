@@ -0,0 +1,219 @@
+package chunkpipe
+
+import "time"
+
+// reaper 是在塊到期時移除它們的背景 goroutine，由 ChunkPipe.mu 保護。
+// 沒有會過期的塊時 goroutine 結束，之後插入會過期的塊時再重新啟動。
+type reaper struct {
+	// 最早的到期時間，零值表示沒有會過期的塊
+	next    time.Time
+	running bool
+	// 有更早到期的塊時喚醒 goroutine 重新計時
+	wake    chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+// WithTTL 讓插入的塊在 d 之後過期，由背景 goroutine 從頭部或中間移除。
+// PushTTL 可以為單次插入指定不同的存活時間。Close 會停止背景 goroutine，
+// 之後過期的塊在下一次讀取或取出數據時移除，OnExpire 在另一個 goroutine 中呼叫。
+// 註冊了 Cursor 時，過期塊之後的 Cursor 位置會隨之前移，讓它們仍指向相同的元素。
+func WithTTL[T any](d time.Duration) Option[T] {
+	return func(cp *ChunkPipe[T]) {
+		cp.ttl = d
+	}
+}
+
+// WithOnExpire 設定在塊過期並被移除後呼叫的函式，chunks 按原本的順序排列。
+// fn 在背景 goroutine 中、不持有鎖時呼叫，不可以在其中呼叫 Close。
+func WithOnExpire[T any](fn func(chunks [][]T)) Option[T] {
+	return func(cp *ChunkPipe[T]) {
+		cp.onExpire = fn
+	}
+}
+
+// PushTTL 插入數據並讓它在 ttl 之後過期，ttl 不大於 0 時不會過期。錯誤與 TryPush 相同。
+func (cl *ChunkPipe[T]) PushTTL(data []T, ttl time.Duration) error {
+	_, err := cl.push(data, nil, ttl)
+	return err
+}
+
+// scheduleExpiry 確保背景 goroutine 在 exp 時醒來，已關閉時只記錄到期時間，需持有寫鎖
+func (cl *ChunkPipe[T]) scheduleExpiry(exp time.Time) {
+	r := cl.reaper
+	if r == nil {
+		r = &reaper{
+			wake: make(chan struct{}, 1),
+		}
+		cl.reaper = r
+	}
+	if r.next.IsZero() || exp.Before(r.next) {
+		r.next = exp
+		select {
+		case r.wake <- struct{}{}:
+		default:
+		}
+	}
+	if !r.running && !cl.closed {
+		r.running = true
+		r.done = make(chan struct{})
+		r.stopped = make(chan struct{})
+		go cl.reap(r, r.done, r.stopped)
+	}
+}
+
+// stopReaper 停止背景 goroutine，返回需要在解鎖後等待的 channel，需持有寫鎖
+func (cl *ChunkPipe[T]) stopReaper() <-chan struct{} {
+	r := cl.reaper
+	if r == nil || !r.running {
+		return nil
+	}
+	r.running = false
+	close(r.done)
+	return r.stopped
+}
+
+// reap 在最早的到期時間移除過期的塊，沒有會過期的塊時結束
+func (cl *ChunkPipe[T]) reap(r *reaper, done <-chan struct{}, stopped chan<- struct{}) {
+	defer close(stopped)
+	for {
+		cl.lock()
+		next := r.next
+		if next.IsZero() {
+			// Close 可能已經停止了 goroutine，不再改動
+			select {
+			case <-done:
+			default:
+				r.running = false
+			}
+			cl.mu.Unlock()
+			return
+		}
+		cl.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-done:
+			timer.Stop()
+			return
+		case <-r.wake:
+			timer.Stop()
+			continue
+		case <-timer.C:
+		}
+		cl.expire(r)
+	}
+}
+
+// expire 移除所有已過期的塊並呼叫 OnExpire
+func (cl *ChunkPipe[T]) expire(r *reaper) {
+	var pe pendingEvent
+	cl.lock()
+	if !r.running {
+		// Close 已經開始等待，不再移除
+		cl.mu.Unlock()
+		return
+	}
+	cl.reclaim()
+	expired, removed := cl.removeExpired(time.Now(), cl.onExpire != nil)
+	pe = cl.event(EventExpire, removed)
+	cl.mu.Unlock()
+	pe.publish()
+
+	if len(expired) > 0 {
+		cl.onExpire(expired)
+	}
+}
+
+// expireClosed 在已關閉的 ChunkPipe 上移除已過期的塊，
+// 在另一個 goroutine 中發布事件並呼叫 OnExpire。需持有寫鎖。
+func (cl *ChunkPipe[T]) expireClosed() {
+	if !cl.hasExpired() {
+		return
+	}
+	expired, removed := cl.removeExpired(time.Now(), cl.onExpire != nil)
+	pe := cl.event(EventExpire, removed)
+	if pe.subs == nil && len(expired) == 0 {
+		return
+	}
+	go func() {
+		pe.publish()
+		if len(expired) > 0 {
+			cl.onExpire(expired)
+		}
+	}()
+}
+
+// hasExpired 回報背景 goroutine 停止後是否有已過期但尚未移除的塊，需持有鎖
+func (cl *ChunkPipe[T]) hasExpired() bool {
+	r := cl.reaper
+	return r != nil && !r.running && !r.next.IsZero() && !time.Now().Before(r.next)
+}
+
+// removeExpired 移除在 now 之前到期的塊並重新計算下一次到期時間，
+// collect 為 true 時返回被移除的塊。需持有寫鎖。
+func (cl *ChunkPipe[T]) removeExpired(now time.Time, collect bool) ([][]T, int) {
+	var expired [][]T
+	var next time.Time
+	// 中間被移除的區間，以移除前的串流偏移表示
+	var gaps [][2]int
+	removed := 0
+	shift := 0
+	start := cl.offset
+	list := cl.list
+	kept := list[:0]
+	for i := range list {
+		c := list[i]
+		end := c.off
+		exp := c.meta.Expires
+		if exp.IsZero() || now.Before(exp) {
+			if !exp.IsZero() && (next.IsZero() || exp.Before(next)) {
+				next = exp
+			}
+			c.off -= shift
+			kept = append(kept, c)
+			start = end
+			continue
+		}
+
+		if collect {
+			expired = append(expired, cl.detach(&c))
+		}
+		cl.release(&list[i])
+		removed += end - start
+		if len(kept) == 0 {
+			// 頭部的塊直接前移 offset，串流偏移不變
+			cl.offset = end
+		} else {
+			gaps = append(gaps, [2]int{start, end})
+			shift += end - start
+		}
+		start = end
+	}
+	clear(list[len(kept):])
+	cl.list = kept
+	cl.reaper.next = next
+
+	if len(gaps) > 0 {
+		for _, cur := range cl.cursors {
+			cur.pos = closeGaps(cur.pos, gaps)
+			cur.committed = closeGaps(cur.committed, gaps)
+		}
+	}
+	return expired, removed
+}
+
+// closeGaps 將移除前的串流偏移換算成移除 gaps 之後的偏移，落在區間內的偏移移到區間開頭
+func closeGaps(pos int, gaps [][2]int) int {
+	shift := 0
+	for _, g := range gaps {
+		if pos <= g[0] {
+			break
+		}
+		if pos < g[1] {
+			return g[0] - shift
+		}
+		shift += g[1] - g[0]
+	}
+	return pos - shift
+}
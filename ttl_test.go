@@ -0,0 +1,215 @@
+package chunkpipe
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTTL(t *testing.T) {
+	t.Run("FrontAndMiddle", func(t *testing.T) {
+		var mu sync.Mutex
+		var expired [][]int
+		done := make(chan struct{}, 4)
+		cp := NewChunkPipe[int](
+			WithTTL[int](20*time.Millisecond),
+			WithOnExpire(func(chunks [][]int) {
+				mu.Lock()
+				expired = append(expired, chunks...)
+				mu.Unlock()
+				done <- struct{}{}
+			}),
+		)
+		defer cp.Close()
+
+		cp.Push([]int{1, 2})
+		cp.PushTTL([]int{3}, 0)
+		cp.Push([]int{4, 5})
+		cp.PushTTL([]int{6}, time.Hour)
+
+		// 兩個塊的到期時間相近，可能在一次或兩次移除中過期
+		deadline := time.After(time.Second)
+		var got [][]int
+		for len(got) < 2 {
+			select {
+			case <-done:
+			case <-deadline:
+				t.Fatal("chunks did not expire")
+			}
+			mu.Lock()
+			got = expired
+			mu.Unlock()
+		}
+		if !reflect.DeepEqual(got, [][]int{{1, 2}, {4, 5}}) {
+			t.Errorf("expired = %v", got)
+		}
+		if v := cp.ValueSlice(); !reflect.DeepEqual(v, []int{3, 6}) {
+			t.Errorf("ValueSlice = %v", v)
+		}
+		if v, ok := cp.Get(1); !ok || v != 6 {
+			t.Errorf("Get(1) = %v, %v", v, ok)
+		}
+		if st := cp.Stats(); st.Pops.Expire == 0 || st.ElementsOut != 4 {
+			t.Errorf("Pops.Expire, ElementsOut = %d, %d", st.Pops.Expire, st.ElementsOut)
+		}
+	})
+
+	t.Run("Cursor", func(t *testing.T) {
+		cp := NewChunkPipe[int]()
+		defer cp.Close()
+		cp.Push([]int{1})
+		cp.PushTTL([]int{2, 3}, time.Millisecond)
+		cp.Push([]int{4, 5})
+		cur := cp.NewCursor()
+		cur.Seek(4)
+		time.Sleep(10 * time.Millisecond)
+
+		// 等待背景 goroutine 移除中間的塊
+		deadline := time.Now().Add(time.Second)
+		for cp.Stats().Len != 3 {
+			if time.Now().After(deadline) {
+				t.Fatal("chunk did not expire")
+			}
+			time.Sleep(time.Millisecond)
+		}
+		if v, ok := cur.Next(); !ok || !reflect.DeepEqual(v, []int{5}) {
+			t.Errorf("cursor Next = %v, %v", v, ok)
+		}
+	})
+
+	t.Run("CloseStopsReaper", func(t *testing.T) {
+		called := make(chan struct{}, 1)
+		cp := NewChunkPipe[int](WithOnExpire(func([][]int) { called <- struct{}{} }))
+		cp.PushTTL([]int{1}, 20*time.Millisecond)
+		stopped := cp.reaper.stopped
+		cp.Close()
+		select {
+		case <-stopped:
+		default:
+			t.Fatal("reaper still running after Close")
+		}
+		time.Sleep(40 * time.Millisecond)
+		select {
+		case <-called:
+			t.Error("OnExpire called by the stopped reaper")
+		default:
+		}
+
+		// 已關閉時過期的塊在取出時移除
+		if v, ok := cp.PopFront(); ok {
+			t.Errorf("PopFront = %d, expired chunk returned", v)
+		}
+		select {
+		case <-called:
+		case <-time.After(time.Second):
+			t.Error("OnExpire not called for chunk expired after Close")
+		}
+		if st := cp.Stats(); st.Len != 0 || st.Pops.Expire != 1 {
+			t.Errorf("Len, Pops.Expire = %d, %d, want 0, 1", st.Len, st.Pops.Expire)
+		}
+	})
+
+	t.Run("ReadAfterClose", func(t *testing.T) {
+		cp := NewChunkPipe[int](WithTTL[int](10 * time.Millisecond))
+		cp.Push([]int{1}).Push([]int{2})
+		cp.Close()
+		time.Sleep(30 * time.Millisecond)
+
+		// 讀取路徑也不返回已過期的塊
+		if v, ok := cp.Get(0); ok {
+			t.Errorf("Get(0) = %d, expired chunk returned", v)
+		}
+		if got := cp.ValueSlice(); len(got) != 0 {
+			t.Errorf("ValueSlice = %v", got)
+		}
+		if _, ok := cp.Front(); ok {
+			t.Error("Front returned an expired element")
+		}
+		if st := cp.Stats(); st.Len != 0 || st.Pops.Expire != 1 {
+			t.Errorf("Len, Pops.Expire = %d, %d, want 0, 1", st.Len, st.Pops.Expire)
+		}
+	})
+
+	t.Run("IdleReaperExits", func(t *testing.T) {
+		cp := NewChunkPipe[int]()
+		cp.PushTTL([]int{1}, 10*time.Millisecond)
+		stopped := cp.reaper.stopped
+		clone := cp.Clone()
+		for _, p := range []*ChunkPipe[int]{cp, clone} {
+			select {
+			case <-p.reaper.stopped:
+			case <-time.After(time.Second):
+				t.Fatal("reaper still running without expiring chunks")
+			}
+		}
+
+		// 再次插入會過期的塊時重新啟動
+		cp.PushTTL([]int{2}, 10*time.Millisecond)
+		if cp.reaper.stopped == stopped {
+			t.Fatal("reaper not restarted")
+		}
+		select {
+		case <-cp.reaper.stopped:
+		case <-time.After(time.Second):
+			t.Fatal("restarted reaper did not exit")
+		}
+		if cp.Stats().Len != 0 {
+			t.Errorf("Len = %d, want 0", cp.Stats().Len)
+		}
+		cp.Close()
+	})
+}
+
+func TestTTLRebuild(t *testing.T) {
+	t.Run("Sort", func(t *testing.T) {
+		cp := NewChunkPipe[int]()
+		cp.Push([]int{3, 1})
+		m, _ := cp.PushMeta([]int{2}, nil)
+		cp.PushTTL([]int{0}, 20*time.Millisecond)
+		SortFunc(cp, func(a, b int) int { return a - b })
+
+		iter := cp.ChunkIter()
+		var metas []ChunkMeta
+		for iter.Next() {
+			metas = append(metas, iter.Meta())
+		}
+		if len(metas) != 3 || metas[1].ID != m.ID || metas[2].Expires.IsZero() {
+			t.Fatalf("metas after sort = %+v", metas)
+		}
+		time.Sleep(60 * time.Millisecond)
+		if got := cp.ValueSlice(); !reflect.DeepEqual(got, []int{0, 1, 2}) {
+			t.Errorf("after expiry = %v, want [0 1 2]", got)
+		}
+		cp.Close()
+	})
+
+	t.Run("Unmarshal", func(t *testing.T) {
+		cp := NewChunkPipe[int](WithTTL[int](20 * time.Millisecond))
+		if err := cp.UnmarshalJSON([]byte("[[1,2],[3]]")); err != nil {
+			t.Fatal(err)
+		}
+		iter := cp.ChunkIter()
+		for iter.Next() {
+			if iter.Meta().ID == 0 || iter.Meta().Expires.IsZero() {
+				t.Errorf("decoded chunk meta = %+v", iter.Meta())
+			}
+		}
+		time.Sleep(60 * time.Millisecond)
+		if n := cp.Stats().Len; n != 0 {
+			t.Errorf("Len = %d, want 0", n)
+		}
+		cp.Close()
+	})
+}
+
+func TestCloseGaps(t *testing.T) {
+	gaps := [][2]int{{2, 4}, {6, 7}}
+	for _, c := range []struct{ pos, want int }{
+		{0, 0}, {2, 2}, {3, 2}, {4, 2}, {5, 3}, {6, 4}, {7, 4}, {9, 6},
+	} {
+		if got := closeGaps(c.pos, gaps); got != c.want {
+			t.Errorf("closeGaps(%d) = %d, want %d", c.pos, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package chunkpipe
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"slices"
+	"unicode/utf8"
+)
+
+// ErrIncompleteRune 表示頭部只有 rune 的一部分，其餘位元組尚未插入
+var ErrIncompleteRune = errors.New("chunkpipe: incomplete rune")
+
+// runeState 記錄最近一次 ReadRune 讀出的 rune，供 UnreadRune 使用
+type runeState struct {
+	buf [utf8.UTFMax]byte
+	n   int
+	// rune 第一個位元組所在塊的中繼資料，放回時沿用
+	meta ChunkMeta
+	// 讀出後的 offset，用來判斷之後是否有其他操作移除了頭部數據
+	off int
+}
+
+// ReadRune 從 ChunkPipe[byte] 的頭部彈出一個 UTF-8 編碼的 rune，rune 可以跨越塊邊界。
+// ChunkPipe 為空時返回 io.EOF；頭部只有不完整的 rune 時，若尚未關閉則返回 ErrIncompleteRune 且不修改數據，
+// 已關閉則返回 utf8.RuneError 並彈出一個位元組。
+func ReadRune(cp *ChunkPipe[byte]) (r rune, size int, err error) {
+	var pe pendingEvent
+	defer pe.publish()
+	cp.lock()
+	defer cp.mu.Unlock()
+	cp.reclaim()
+
+	// rune 最多跨越 UTFMax 個塊
+	var head [utf8.UTFMax][]byte
+	n := min(len(cp.list), len(head))
+	for i := 0; i < n; i++ {
+		head[i] = cp.load(&cp.list[i])
+	}
+	cur := runeCursor{chunks: head[:n]}
+	r, size, complete := cur.next()
+	if size == 0 {
+		return 0, 0, io.EOF
+	}
+	if !complete && !cp.closed {
+		return 0, 0, ErrIncompleteRune
+	}
+
+	st := cp.lastRune
+	if st == nil {
+		st = &runeState{}
+		cp.lastRune = st
+	}
+	st.meta = cp.list[0].meta
+	views, n := cp.removeFront(size, true)
+	pe = cp.event(EventPopFront, n)
+	st.n = 0
+	for _, v := range views {
+		st.n += copy(st.buf[st.n:], v)
+	}
+	st.off = cp.offset
+	return r, size, nil
+}
+
+// UnreadRune 將最近一次 ReadRune 讀出的 rune 放回頭部。
+// 在那之後若有其他操作從頭部移除了數據，返回 bufio.ErrInvalidUnreadRune。
+func UnreadRune(cp *ChunkPipe[byte]) error {
+	var pe pendingEvent
+	defer pe.publish()
+	cp.lock()
+	defer cp.mu.Unlock()
+
+	st := cp.lastRune
+	if st == nil || st.n == 0 || st.off != cp.offset {
+		return bufio.ErrInvalidUnreadRune
+	}
+
+	pe = cp.insertFront(slices.Clone(st.buf[:st.n]), st.meta)
+	st.n = 0
+	return nil
+}
+
+// RuneCount 返回 ChunkPipe[byte] 中的 rune 數，無效或不完整的位元組各算一個 rune
+func RuneCount(cp *ChunkPipe[byte]) int {
+	cur := runeCursor{chunks: cp.snapshot()}
+	n := 0
+	for {
+		if _, size, _ := cur.next(); size == 0 {
+			return n
+		}
+		n++
+	}
+}
+
+// ValidUTF8 回報 ChunkPipe[byte] 的內容是否為有效的 UTF-8，會逐塊串流檢查
+func ValidUTF8(cp *ChunkPipe[byte]) bool {
+	var pending []byte
+	for _, c := range cp.snapshot() {
+		if len(pending) != 0 {
+			// 以下一個塊補齊前一個塊尾部不完整的 rune
+			n := min(len(c), utf8.UTFMax-len(pending))
+			pending = append(pending, c[:n]...)
+			if !utf8.FullRune(pending) {
+				continue
+			}
+			r, size := utf8.DecodeRune(pending)
+			if r == utf8.RuneError && size == 1 {
+				return false
+			}
+			c = c[size-(len(pending)-n):]
+			pending = pending[:0]
+		}
+		body, tail := splitIncompleteRune(c)
+		if !utf8.Valid(body) {
+			return false
+		}
+		pending = append(pending, tail...)
+	}
+	return len(pending) == 0
+}
+
+// Strings 返回逐塊產生字串的迭代器，跨越塊邊界的 rune 會被完整地放在同一個字串中
+func Strings(cp *ChunkPipe[byte]) *StringIterator {
+	return &StringIterator{chunks: cp.snapshot()}
+}
+
+// StringIterator 逐塊返回 ChunkPipe[byte] 快照的內容
+type StringIterator struct {
+	chunks [][]byte
+	carry  []byte
+	cur    string
+}
+
+// Next 移到下一個字串，沒有更多數據時返回 false
+func (it *StringIterator) Next() bool {
+	for len(it.chunks) != 0 {
+		c := it.chunks[0]
+		it.chunks = it.chunks[1:]
+
+		data := c
+		if len(it.carry) != 0 {
+			data = append(it.carry, c...)
+			it.carry = nil
+		}
+		body, tail := splitIncompleteRune(data)
+		if len(it.chunks) == 0 {
+			body, tail = data, nil
+		}
+		it.carry = slices.Clone(tail)
+		if len(body) != 0 {
+			it.cur = string(body)
+			return true
+		}
+	}
+	if len(it.carry) != 0 {
+		it.cur = string(it.carry)
+		it.carry = nil
+		return true
+	}
+	it.cur = ""
+	return false
+}
+
+// V 返回目前的字串
+func (it *StringIterator) V() string {
+	return it.cur
+}
+
+// splitIncompleteRune 將 b 分為完整的部分與尾部不完整的 rune
+func splitIncompleteRune(b []byte) (body, tail []byte) {
+	for i := len(b) - 1; i >= 0 && i > len(b)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(b[i]) {
+			if !utf8.FullRune(b[i:]) {
+				return b[:i], b[i:]
+			}
+			break
+		}
+	}
+	return b, nil
+}
+
+// runeCursor 逐 rune 走訪塊，能處理跨越塊邊界的 rune
+type runeCursor struct {
+	chunks [][]byte
+	pos    int
+}
+
+// next 返回下一個 rune 並前進，沒有數據時 size 為 0。
+// complete 為 false 表示剩餘數據只是 rune 的開頭部分。
+func (c *runeCursor) next() (r rune, size int, complete bool) {
+	for len(c.chunks) != 0 && c.pos >= len(c.chunks[0]) {
+		c.chunks = c.chunks[1:]
+		c.pos = 0
+	}
+	if len(c.chunks) == 0 {
+		return 0, 0, true
+	}
+
+	cur := c.chunks[0][c.pos:]
+	if utf8.FullRune(cur) {
+		r, size = utf8.DecodeRune(cur)
+		c.pos += size
+		return r, size, true
+	}
+
+	// rune 跨越塊邊界，收集後續塊的位元組
+	var buf [utf8.UTFMax]byte
+	n := copy(buf[:], cur)
+	for _, next := range c.chunks[1:] {
+		if n == len(buf) {
+			break
+		}
+		n += copy(buf[n:], next)
+	}
+	complete = utf8.FullRune(buf[:n])
+	r, size = utf8.DecodeRune(buf[:n])
+
+	for skip := size; skip > 0; {
+		k := min(skip, len(c.chunks[0])-c.pos)
+		skip -= k
+		c.pos += k
+		if c.pos == len(c.chunks[0]) {
+			c.chunks = c.chunks[1:]
+			c.pos = 0
+		}
+	}
+	return r, size, complete
+}
@@ -0,0 +1,106 @@
+package chunkpipe
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestUTF8(t *testing.T) {
+	// "héllo, 世界🙂" 在多位元組 rune 中間切分
+	text := "héllo, 世界🙂"
+	raw := []byte(text)
+	newPipe := func() *ChunkPipe[byte] {
+		cp := NewChunkPipe[byte]()
+		cp.Push(raw[:2]).Push(raw[2:9]).Push(raw[9:10]).Push(raw[10:13]).Push(raw[13:15]).Push(raw[15:])
+		return cp
+	}
+
+	t.Run("RuneCount", func(t *testing.T) {
+		if got := RuneCount(newPipe()); got != 10 {
+			t.Errorf("RuneCount = %d, want 10", got)
+		}
+	})
+
+	t.Run("ValidUTF8", func(t *testing.T) {
+		if !ValidUTF8(newPipe()) {
+			t.Error("ValidUTF8 should be true")
+		}
+		cp := newPipe()
+		cp.PopEnd()
+		if ValidUTF8(cp) {
+			t.Error("ValidUTF8 should be false for a truncated rune")
+		}
+		if ValidUTF8(newBytePipe("a\xffb")) {
+			t.Error("ValidUTF8 should be false for invalid bytes")
+		}
+	})
+
+	t.Run("Strings", func(t *testing.T) {
+		var sb strings.Builder
+		it := Strings(newPipe())
+		for it.Next() {
+			if s := it.V(); !strings.ContainsRune(s, '�') && s != "" {
+				sb.WriteString(s)
+			} else {
+				t.Errorf("Strings produced a split rune: %q", s)
+			}
+		}
+		if sb.String() != text {
+			t.Errorf("Strings = %q, want %q", sb.String(), text)
+		}
+	})
+
+	t.Run("ReadRune", func(t *testing.T) {
+		cp := newPipe()
+		var got []rune
+		for {
+			r, size, err := ReadRune(cp)
+			if err == io.EOF {
+				break
+			}
+			if err != nil || size == 0 {
+				t.Fatalf("ReadRune = %q, %d, %v", r, size, err)
+			}
+			got = append(got, r)
+		}
+		if string(got) != text {
+			t.Errorf("ReadRune = %q, want %q", string(got), text)
+		}
+	})
+
+	t.Run("UnreadRune", func(t *testing.T) {
+		cp := newPipe()
+		ReadRune(cp)
+		r, _, _ := ReadRune(cp)
+		if err := UnreadRune(cp); err != nil {
+			t.Fatal(err)
+		}
+		if err := UnreadRune(cp); err != bufio.ErrInvalidUnreadRune {
+			t.Errorf("second UnreadRune = %v", err)
+		}
+		if r2, _, _ := ReadRune(cp); r2 != r || r != 'é' {
+			t.Errorf("ReadRune after UnreadRune = %q, want %q", r2, r)
+		}
+		cp.PopFront()
+		if err := UnreadRune(cp); err != bufio.ErrInvalidUnreadRune {
+			t.Errorf("UnreadRune after PopFront = %v", err)
+		}
+		if v, _ := cp.Get(0); v != 'l' {
+			t.Errorf("Get(0) = %q", v)
+		}
+	})
+
+	t.Run("Incomplete", func(t *testing.T) {
+		cp := NewChunkPipe[byte]()
+		cp.Push(raw[8:10])
+		if _, _, err := ReadRune(cp); err != ErrIncompleteRune {
+			t.Errorf("ReadRune = %v, want ErrIncompleteRune", err)
+		}
+		cp.Close()
+		if r, size, err := ReadRune(cp); err != nil || r != '�' || size != 1 {
+			t.Errorf("ReadRune after Close = %q, %d, %v", r, size, err)
+		}
+	})
+}